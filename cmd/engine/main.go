@@ -2,7 +2,11 @@ package main
 
 import (
     "fmt"
+    "log"
     "net/http"
+    "strconv"
+    "strings"
+    "sync"
     "time"
     
     "github.com/gin-gonic/gin"
@@ -10,15 +14,20 @@ import (
 
 
     "github.com/colmak/go-chess-go/pkg/board"
+    "github.com/colmak/go-chess-go/pkg/render"
 )
 
-// Global board instance to keep track of the game state
-var gameBoard *board.Board
+// Global board instance to keep track of the game state. Gin serves each
+// request on its own goroutine, so handlers hold gameMu while they use it.
+var (
+    gameBoard *board.Board
+    gameMu    sync.Mutex
+)
 
 func initialize() {
     fmt.Println("Initializing the Go Chess Go Engine")
     gameBoard = board.NewBoard() // Initialize the board
-    gameBoard.PrintBoard()       // Optionally print the initial board state
+    logBoard()                   // Log the initial board state
 }
 
 func main() {
@@ -40,19 +49,41 @@ func main() {
     // API routes
     r.GET("/status", getStatus)
     r.POST("/move", makeMove)
+    r.POST("/undo", undoMove)
     r.POST("/reset", resetGame)
+    r.POST("/resign", resign)
+    r.POST("/draw", draw)
+    r.GET("/board.svg", boardSVG)
 
     // Start the API server on port 8080
     r.Run(":8080")
 }
 
 
+// logBoard writes the board to the server log, marking the last move and any
+// check. The caller holds gameMu.
+func logBoard() {
+    log.Printf("Board, %s to move:\n%s", gameBoard.CurrentTurn, gameBoard.Render(board.RenderOptions{
+        Unicode:     true,
+        Coordinates: true,
+        LastMove:    true,
+        Check:       true,
+    }))
+}
+
 // getStatus returns the current state of the board
 func getStatus(c *gin.Context) {
-    gameBoard.PrintBoard()
+    gameMu.Lock()
+    defer gameMu.Unlock()
+    logBoard()
+    status, termination := gameBoard.Status()
     c.JSON(http.StatusOK, gin.H{
-        "board": gameBoard.Squares, // Return the board's squares array
-        "turn":  gameBoard.CurrentTurn,
+        "board":       gameBoard.Squares, // Return the board's squares array
+        "turn":        gameBoard.CurrentTurn,
+        "fen":         gameBoard.FEN(),
+        "status":      status.String(),
+        "result":      status.Result(),
+        "termination": termination.String(),
     })
 }
 
@@ -62,6 +93,11 @@ type Move struct {
     StartCol int `json:"start_col"`
     EndRow   int `json:"end_row"`
     EndCol   int `json:"end_col"`
+    // Promotion is the piece a pawn promotes to: "q", "r", "b" or "n"
+    Promotion string `json:"promotion,omitempty"`
+    // UCI is the move in coordinate notation, such as "e2e4" or "e7e8q".
+    // When set, the row and column fields are ignored.
+    UCI string `json:"uci,omitempty"`
 }
 
 func makeMove(c *gin.Context) {
@@ -71,31 +107,295 @@ func makeMove(c *gin.Context) {
         return
     }
 
-    start := board.Position{Row: move.StartRow, Col: move.StartCol}
-    end := board.Position{Row: move.EndRow, Col: move.EndCol}
+    gameMu.Lock()
+    defer gameMu.Unlock()
+    if gameBoard.IsGameOver() {
+        c.JSON(http.StatusConflict, gin.H{
+            "message": "Invalid move",
+            "error":   board.ErrGameOver.Error(),
+        })
+        return
+    }
+
+    var played board.Move
+    if move.UCI != "" {
+        m, err := board.ParseUCIMove(gameBoard, strings.ToLower(move.UCI))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "message": "Invalid move",
+                "error":   err.Error(),
+            })
+            return
+        }
+        gameBoard.MakeMove(m)
+        played = m
+    } else {
+        var promotion board.PieceType
+        if move.Promotion != "" {
+            var err error
+            if promotion, err = board.ParsePromotion(strings.ToLower(move.Promotion)); err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{
+                    "message": "Invalid move",
+                    "error":   err.Error(),
+                })
+                return
+            }
+        }
 
-    fmt.Printf("Current turn before move: %d\n", gameBoard.CurrentTurn)
-    fmt.Printf("Switching turn after move...\n")
+        played = board.Move{
+            Start:     board.Position{Row: move.StartRow, Col: move.StartCol},
+            End:       board.Position{Row: move.EndRow, Col: move.EndCol},
+            Promotion: promotion,
+        }
+        if err := gameBoard.TryMove(played); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "message": "Invalid move",
+                "error":   err.Error(),
+            })
+            return
+        }
+    }
 
-    if gameBoard.MovePiece(start, end) {
-        c.JSON(http.StatusOK, gin.H{
-            "message": "Move successful",
-            "board":   gameBoard.Squares,
-            "turn":    gameBoard.CurrentTurn,
-        })
+    logBoard() // Log the board after the move
+    status, termination := gameBoard.Status()
+    c.JSON(http.StatusOK, gin.H{
+        "message":     "Move successful",
+        "move":        played.UCI(),
+        "board":       gameBoard.Squares,
+        "turn":        gameBoard.CurrentTurn,
+        "status":      status.String(),
+        "result":      status.Result(),
+        "termination": termination.String(),
+    })
+}
+
+// Resign struct for receiving the side that resigns
+type Resign struct {
+    // Color is "white" or "black"; it defaults to the side to move
+    Color *board.Color `json:"color"`
+}
+
+// resign ends the game with a resignation
+func resign(c *gin.Context) {
+    var req Resign
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+    gameMu.Lock()
+    defer gameMu.Unlock()
+    color := gameBoard.CurrentTurn
+    if req.Color != nil {
+        color = *req.Color
+    }
+    endGame(c, gameBoard.Resign(color))
+}
+
+// Draw struct for receiving how a draw comes about
+type Draw struct {
+    // Agreement ends the game by mutual agreement; otherwise the side to
+    // move claims a draw by threefold repetition or the 50-move rule
+    Agreement bool `json:"agreement"`
+}
+
+// draw ends the game in a draw by agreement or claim
+func draw(c *gin.Context) {
+    var req Draw
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+    gameMu.Lock()
+    defer gameMu.Unlock()
+    if req.Agreement {
+        endGame(c, gameBoard.AgreeDraw())
     } else {
+        endGame(c, gameBoard.ClaimDraw())
+    }
+}
+
+// endGame reports the result of ending the game early. The caller holds
+// gameMu.
+func endGame(c *gin.Context, err error) {
+    if err != nil {
+        c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+        return
+    }
+    status, termination := gameBoard.Status()
+    c.JSON(http.StatusOK, gin.H{
+        "message":     "Game over",
+        "status":      status.String(),
+        "result":      status.Result(),
+        "termination": termination.String(),
+    })
+}
+
+// undoMove takes back the last move
+func undoMove(c *gin.Context) {
+    gameMu.Lock()
+    defer gameMu.Unlock()
+    // A takeback would silently reopen a game that was resigned, lost on
+    // time or drawn by agreement or claim
+    if ending := gameBoard.Ending(); ending.Termination != board.NotTerminated {
+        c.JSON(http.StatusConflict, gin.H{
+            "message":     "Cannot undo",
+            "error":       board.ErrGameOver.Error(),
+            "termination": ending.Termination.String(),
+        })
+        return
+    }
+    if _, ok := gameBoard.UnmakeMove(); !ok {
         c.JSON(http.StatusBadRequest, gin.H{
-            "message": "Invalid move",
+            "message": "No move to undo",
         })
+        return
     }
+    c.JSON(http.StatusOK, gin.H{
+        "message": "Move undone",
+        "board":   gameBoard.Squares,
+        "turn":    gameBoard.CurrentTurn,
+    })
 }
 
-// resetGame resets the chess game
+// Reset struct for receiving an optional starting position
+type Reset struct {
+    FEN      string `json:"fen"`
+    Chess960 *int   `json:"chess960,omitempty"` // Chess960 start position, 0 to 959
+}
+
+// resetGame resets the chess game, optionally to the position given as FEN
+// or to a Chess960 start position
 func resetGame(c *gin.Context) {
-    gameBoard = board.NewBoard() // Reinitialize the board
-    gameBoard.PrintBoard()       // Optionally print the reset board
+    var reset Reset
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&reset); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+    }
+
+    gameMu.Lock()
+    defer gameMu.Unlock()
+    switch {
+    case reset.Chess960 != nil:
+        b, err := board.NewChess960Board(*reset.Chess960)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        gameBoard = b
+    case reset.FEN == "":
+        gameBoard = board.NewBoard() // Reinitialize the board
+    default:
+        b, err := board.FromFEN(reset.FEN)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        gameBoard = b
+    }
+    logBoard() // Log the reset board
     c.JSON(http.StatusOK, gin.H{
         "message": "Game reset",
         "board":   gameBoard.Squares,
+        "fen":     gameBoard.FEN(),
     })
 }
+
+// boardSVG draws the current position, or the one given by the fen query
+// parameter, as an SVG image. Query parameters:
+//
+//	fen          position to draw instead of the current game
+//	orientation  "white" or "black" at the bottom
+//	size         width and height in pixels
+//	coordinates  label the files and ranks (default true)
+//	lastmove     shade the last move of the current game (default true)
+//	squares      comma-separated squares to highlight, such as "e4,d5"
+//	arrows       comma-separated arrows, such as "e2e4,g1f3"
+func boardSVG(c *gin.Context) {
+    gameMu.Lock()
+    defer gameMu.Unlock()
+    b := gameBoard
+    opts := render.Options{Coordinates: true, LastMove: true, Check: true}
+    if fen := c.Query("fen"); fen != "" {
+        parsed, err := board.FromFEN(fen)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        b = parsed
+    }
+
+    if orientation := c.Query("orientation"); orientation != "" {
+        switch orientation {
+        case "white":
+            opts.Orientation = board.White
+        case "black":
+            opts.Orientation = board.Black
+        default:
+            c.JSON(http.StatusBadRequest, gin.H{"error": "orientation must be white or black"})
+            return
+        }
+    }
+    if size := c.Query("size"); size != "" {
+        n, err := strconv.Atoi(size)
+        if err != nil || n < 16 || n > 2048 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "size must be a number of pixels from 16 to 2048"})
+            return
+        }
+        opts.Size = n
+    }
+    for name, flag := range map[string]*bool{"coordinates": &opts.Coordinates, "lastmove": &opts.LastMove} {
+        if value := c.Query(name); value != "" {
+            v, err := strconv.ParseBool(value)
+            if err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be true or false", name)})
+                return
+            }
+            *flag = v
+        }
+    }
+
+    for _, name := range splitList(c.Query("squares")) {
+        pos, err := board.ParsePosition(name)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        opts.Highlights = append(opts.Highlights, pos)
+    }
+    for _, arrow := range splitList(c.Query("arrows")) {
+        if len(arrow) != 4 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid arrow %q", arrow)})
+            return
+        }
+        from, err := board.ParsePosition(arrow[:2])
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        to, err := board.ParsePosition(arrow[2:])
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        opts.Arrows = append(opts.Arrows, render.Arrow{From: from, To: to})
+    }
+
+    c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", []byte(render.SVG(b, opts)))
+}
+
+// splitList splits a comma-separated query parameter, ignoring empty items
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(strings.ToLower(item)); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
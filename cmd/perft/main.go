@@ -0,0 +1,51 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "sort"
+    "time"
+
+    "github.com/colmak/go-chess-go/pkg/board"
+    "github.com/colmak/go-chess-go/pkg/uci"
+)
+
+// perft prints the number of leaf nodes below each legal move of a position,
+// followed by the total, in the same format as other engines' divide output.
+func main() {
+    fen := flag.String("fen", board.StartFEN, "position to search, in FEN")
+    depth := flag.Int("depth", 4, "search depth in plies")
+    flag.Parse()
+
+    b, err := board.FromFEN(*fen)
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
+    if *depth < 1 {
+        fmt.Fprintln(os.Stderr, "depth must be at least 1")
+        os.Exit(1)
+    }
+
+    start := time.Now()
+    counts := b.Divide(*depth)
+    elapsed := time.Since(start)
+
+    moves := make([]string, 0, len(counts))
+    nodesByMove := make(map[string]uint64, len(counts))
+    var total uint64
+    for move, nodes := range counts {
+        name := uci.FormatMove(move)
+        moves = append(moves, name)
+        nodesByMove[name] = nodes
+        total += nodes
+    }
+    sort.Strings(moves)
+
+    for _, move := range moves {
+        fmt.Printf("%s: %d\n", move, nodesByMove[move])
+    }
+    fmt.Printf("\nNodes searched: %d\n", total)
+    fmt.Printf("Time: %v (%.0f nodes/s)\n", elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+}
@@ -1,8 +1,228 @@
 // internal/eval/eval.go
 package eval
 
-// Evaluate calculates a score for the current board position.
-func Evaluate() int {
-    // Implement evaluation logic (e.g., material count)
-    return 0
+import (
+    "fmt"
+    "strings"
+    "sync"
+
+    "github.com/colmak/go-chess-go/pkg/board"
+)
+
+// Weights holds every tunable number of the evaluation, in centipawns.
+type Weights struct {
+    // Material is the value of each piece type, indexed by board.PieceType.
+    Material [7]Score
+    // PST is a bonus per piece type and square, indexed by board.PieceType
+    // and board.Square for a White piece. Black pieces use the square
+    // mirrored across the middle of the board.
+    PST [7][64]Score
+    // Phase is how much each piece type counts towards the middlegame,
+    // indexed by board.PieceType. With all pieces on the board the phase is
+    // MaxPhase.
+    Phase [7]int
+    Pawns      PawnWeights
+    KingSafety KingSafetyWeights
+}
+
+// DefaultWeights are the weights used by Evaluate.
+var DefaultWeights = Weights{
+    Material: [7]Score{
+        board.Pawn:   S(100, 120),
+        board.Knight: S(320, 300),
+        board.Bishop: S(330, 320),
+        board.Rook:   S(500, 520),
+        board.Queen:  S(900, 930),
+    },
+    PST: [7][64]Score{
+        board.Pawn:   pst(pawnTable, pawnEndgameTable),
+        board.Knight: pst(knightTable, knightTable),
+        board.Bishop: pst(bishopTable, bishopTable),
+        board.Rook:   pst(rookTable, rookTable),
+        board.Queen:  pst(queenTable, queenTable),
+        board.King:   pst(kingTable, kingEndgameTable),
+    },
+    Phase: [7]int{
+        board.Knight: 1,
+        board.Bishop: 1,
+        board.Rook:   2,
+        board.Queen:  4,
+    },
+    Pawns:      defaultPawnWeights,
+    KingSafety: defaultKingSafetyWeights,
+}
+
+// Evaluator scores positions with a set of weights. It keeps a pawn hash
+// table, so it is not safe for concurrent use, and its Weights should not
+// change once it has evaluated a position.
+type Evaluator struct {
+    Weights    Weights
+    pawns      *pawnHashTable
+    startPhase int // The phase weights of the starting material
+}
+
+// startingPieces is how many pieces of each type a side starts with, indexed
+// by board.PieceType.
+var startingPieces = [7]int{
+    board.Rook:   2,
+    board.Knight: 2,
+    board.Bishop: 2,
+    board.Queen:  1,
+    board.King:   1,
+    board.Pawn:   8,
+}
+
+// New returns an evaluator using w.
+func New(w Weights) *Evaluator {
+    e := &Evaluator{Weights: w, pawns: newPawnHashTable()}
+    for pieceType, n := range startingPieces {
+        e.startPhase += 2 * n * w.Phase[pieceType]
+    }
+    return e
+}
+
+var (
+    defaultEvaluator = New(DefaultWeights)
+    defaultMu        sync.Mutex
+)
+
+// Evaluate returns the score of b in centipawns from the point of view of
+// the side to move, using DefaultWeights. It is safe for concurrent use.
+func Evaluate(b *board.Board) int {
+    defaultMu.Lock()
+    defer defaultMu.Unlock()
+    return defaultEvaluator.Evaluate(b)
+}
+
+// Evaluate returns the score of b in centipawns from the point of view of
+// the side to move.
+func (e *Evaluator) Evaluate(b *board.Board) int {
+    return e.evaluate(b, nil)
+}
+
+// Phase returns the game phase of b, from MaxPhase with all pieces on the
+// board down to 0 when only pieces without a phase weight, by default the
+// kings and pawns, are left.
+func (e *Evaluator) Phase(b *board.Board) int {
+    if e.startPhase <= 0 {
+        return 0 // No piece counts towards the middlegame
+    }
+    phase := 0
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            phase += e.Weights.Phase[b.Squares[row][col].Type()]
+        }
+    }
+    return min(phase, e.startPhase) * MaxPhase / e.startPhase
+}
+
+// Term is one part of the evaluation for each side.
+type Term struct {
+    Name  string
+    White Score
+    Black Score
+}
+
+// Trace breaks an evaluation down into its terms.
+type Trace struct {
+    Terms []Term
+    Phase int
+    Score int // The result of Evaluate, from the side to move's point of view
+}
+
+// Trace evaluates b and records every term.
+func (e *Evaluator) Trace(b *board.Board) Trace {
+    var t Trace
+    t.Score = e.evaluate(b, &t)
+    return t
+}
+
+// String formats the trace as a table with one term per line. Each side's
+// middlegame and endgame values are followed by the difference blended by
+// the phase.
+func (t Trace) String() string {
+    var sb strings.Builder
+    fmt.Fprintf(&sb, "%-16s %13s %13s %7s\n", "term", "white", "black", "total")
+    for _, term := range t.Terms {
+        fmt.Fprintf(&sb, "%-16s %13s %13s %7d\n", term.Name, term.White, term.Black, term.White.Sub(term.Black).Taper(t.Phase))
+    }
+    fmt.Fprintf(&sb, "%-16s %35d\n", "phase", t.Phase)
+    fmt.Fprintf(&sb, "%-16s %35d\n", "side to move", t.Score)
+    return sb.String()
+}
+
+// evaluate computes the score and, if trace is not nil, records its terms.
+func (e *Evaluator) evaluate(b *board.Board, trace *Trace) int {
+    var material, placement [2]Score
+    var pawns [2]uint64
+    var occupied uint64
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            piece := b.Squares[row][col]
+            if piece == board.NoPiece {
+                continue
+            }
+            side, sq := 0, board.Position{Row: row, Col: col}.Square()
+            if piece.Color() == board.Black {
+                side = 1
+            }
+            occupied |= 1 << uint(sq)
+            if piece.Type() == board.Pawn {
+                pawns[side] |= 1 << uint(sq)
+            }
+            if side == 1 {
+                sq = mirror(sq)
+            }
+            material[side] = material[side].Add(e.Weights.Material[piece.Type()])
+            placement[side] = placement[side].Add(e.Weights.PST[piece.Type()][sq])
+        }
+    }
+
+    // The trace bypasses the pawn hash table so that it shows every term
+    var entry *pawnEntry
+    if trace != nil {
+        fresh := evaluatePawns(pawns, &e.Weights.Pawns)
+        entry = &fresh
+    } else {
+        entry = e.pawns.probe(b.PawnHash(), pawns, &e.Weights.Pawns)
+    }
+    pawnTerms := entry.terms
+    for side := 0; side < 2; side++ {
+        free := freePathBonus(entry.passed[side], side, occupied, &e.Weights.Pawns)
+        pawnTerms[passedTerm][side] = pawnTerms[passedTerm][side].Add(free)
+    }
+
+    kingTerms := evaluateKingSafety(b.Bitboards(), &e.Weights.KingSafety)
+
+    phase := e.Phase(b)
+    if trace != nil {
+        trace.Phase = phase
+        trace.Terms = append(trace.Terms,
+            Term{"material", material[0], material[1]},
+            Term{"placement", placement[0], placement[1]},
+        )
+        for i, term := range pawnTerms {
+            trace.Terms = append(trace.Terms, Term{pawnTermNames[i], term[0], term[1]})
+        }
+        for i, term := range kingTerms {
+            trace.Terms = append(trace.Terms, Term{kingTermNames[i], term[0], term[1]})
+        }
+    }
+    total := material[0].Sub(material[1]).Add(placement[0]).Sub(placement[1])
+    for _, term := range pawnTerms {
+        total = total.Add(term[0]).Sub(term[1])
+    }
+    for _, term := range kingTerms {
+        total = total.Add(term[0]).Sub(term[1])
+    }
+    score := total.Taper(phase)
+    if b.CurrentTurn == board.Black {
+        return -score
+    }
+    return score
+}
+
+// mirror returns the square on the same file and the opposite rank.
+func mirror(sq board.Square) board.Square {
+    return sq ^ 56
 }
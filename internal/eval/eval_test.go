@@ -2,7 +2,11 @@
 package eval_test // Adjust the package name according to the folder, e.g., board_test, uci_test, etc.
 
 import (
+    "strings"
     "testing"
+
+    "github.com/colmak/go-chess-go/internal/eval"
+    "github.com/colmak/go-chess-go/pkg/board"
 )
 
 // TestMain initializes the package and verifies no errors during startup.
@@ -34,3 +38,374 @@ func TestBasicFunctionality(t *testing.T) {
         t.Errorf("Basic functionality failed; expected 2, got something else")
     }
 }
+
+// mirrorFEN flips a position vertically and swaps the colors of every piece,
+// keeping the side to move.
+func mirrorFEN(fen string) string {
+    fields := strings.Fields(fen)
+    ranks := strings.Split(fields[0], "/")
+    for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+        ranks[i], ranks[j] = ranks[j], ranks[i]
+    }
+    fields[0] = swapCase(strings.Join(ranks, "/"))
+    if fields[2] != "-" {
+        fields[2] = swapCase(fields[2])
+    }
+    if fields[3] != "-" {
+        fields[3] = fields[3][:1] + string('9'-fields[3][1]+'0')
+    }
+    return strings.Join(fields, " ")
+}
+
+func swapCase(s string) string {
+    return strings.Map(func(r rune) rune {
+        switch {
+        case r >= 'a' && r <= 'z':
+            return r - 'a' + 'A'
+        case r >= 'A' && r <= 'Z':
+            return r - 'A' + 'a'
+        }
+        return r
+    }, s)
+}
+
+var evalPositions = []string{
+    board.StartFEN,
+    "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+    "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+    "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 b - - 0 1",
+    "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+}
+
+func TestEvaluateStartPosition(t *testing.T) {
+    if got := eval.Evaluate(board.NewBoard()); got != 0 {
+        t.Errorf("Expected the start position to evaluate to 0, got %d", got)
+    }
+}
+
+func TestEvaluateSymmetry(t *testing.T) {
+    for _, fen := range evalPositions {
+        b, err := board.FromFEN(fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        mirrored, err := board.FromFEN(mirrorFEN(fen))
+        if err != nil {
+            t.Fatalf("%s: %v", mirrorFEN(fen), err)
+        }
+        if got, want := eval.Evaluate(mirrored), -eval.Evaluate(b); got != want {
+            t.Errorf("%s: expected the mirrored position to score %d, got %d", fen, want, got)
+        }
+
+        // Handing the move to the other side negates the score as well
+        mirrored.CurrentTurn = mirrored.CurrentTurn.Opponent()
+        if got, want := eval.Evaluate(mirrored), eval.Evaluate(b); got != want {
+            t.Errorf("%s: expected the mirrored position with the other side to move to score %d, got %d", fen, want, got)
+        }
+    }
+}
+
+func TestEvaluateMaterial(t *testing.T) {
+    b, err := board.FromFEN("4k3/8/8/8/8/8/8/3QK3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := eval.Evaluate(b); got < 800 {
+        t.Errorf("Expected an extra queen to be worth about 900, got %d", got)
+    }
+    b.CurrentTurn = board.Black
+    if got := eval.Evaluate(b); got > -800 {
+        t.Errorf("Expected Black to be losing, got %d", got)
+    }
+}
+
+func TestTrace(t *testing.T) {
+    e := eval.New(eval.DefaultWeights)
+    for _, fen := range evalPositions {
+        b, err := board.FromFEN(fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        trace := e.Trace(b)
+        if trace.Score != e.Evaluate(b) {
+            t.Errorf("%s: trace score %d differs from Evaluate %d", fen, trace.Score, e.Evaluate(b))
+        }
+        var sum eval.Score
+        for _, term := range trace.Terms {
+            sum = sum.Add(term.White).Sub(term.Black)
+        }
+        total := sum.Taper(trace.Phase)
+        if b.CurrentTurn == board.Black {
+            total = -total
+        }
+        if total != trace.Score {
+            t.Errorf("%s: terms add up to %d, want %d\n%s", fen, total, trace.Score, trace)
+        }
+    }
+}
+
+func TestWeightsAreTunable(t *testing.T) {
+    w := eval.DefaultWeights
+    w.Material[board.Queen] = w.Material[board.Queen].Add(eval.S(100, 100))
+    b, err := board.FromFEN("4k3/8/8/8/8/8/8/3QK3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, base := eval.New(w).Evaluate(b), eval.Evaluate(b); got != base+100 {
+        t.Errorf("Expected a heavier queen to add 100, got %d and %d", got, base)
+    }
+    if eval.DefaultWeights.Material[board.Queen] != eval.S(900, 930) {
+        t.Error("Expected the default weights to be left alone")
+    }
+}
+
+func TestPhase(t *testing.T) {
+    e := eval.New(eval.DefaultWeights)
+    tests := []struct {
+        fen  string
+        want int
+    }{
+        {board.StartFEN, eval.MaxPhase},
+        {"4k3/pppp4/8/8/8/8/PPPP4/4K3 w - - 0 1", 0},
+        {"3qk3/8/8/8/8/8/8/2R1K1N1 w - - 0 1", 7},
+        {"qqqqk3/8/8/8/8/8/8/QQQQK3 w - - 0 1", eval.MaxPhase},
+    }
+    for _, tt := range tests {
+        b, err := board.FromFEN(tt.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := e.Phase(b); got != tt.want {
+            t.Errorf("%s: expected phase %d, got %d", tt.fen, tt.want, got)
+        }
+    }
+}
+
+// Retuned phase weights still span the whole range from the starting
+// material down to bare kings.
+func TestPhaseFollowsWeights(t *testing.T) {
+    w := eval.DefaultWeights
+    w.Phase = [7]int{board.Knight: 3, board.Bishop: 3, board.Rook: 5, board.Queen: 10, board.Pawn: 1}
+    e := eval.New(w)
+    tests := []struct {
+        fen  string
+        want int
+    }{
+        {board.StartFEN, eval.MaxPhase},
+        {"4k3/8/8/8/8/8/8/4K3 w - - 0 1", 0},
+        // Only the pieces of one side are left, half the starting weight
+        {"4k3/8/8/8/8/8/PPPPPPPP/RNBQKBNR w KQ - 0 1", eval.MaxPhase / 2},
+    }
+    for _, tt := range tests {
+        b, err := board.FromFEN(tt.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := e.Phase(b); got != tt.want {
+            t.Errorf("%s: expected phase %d, got %d", tt.fen, tt.want, got)
+        }
+    }
+}
+
+func TestTaper(t *testing.T) {
+    s := eval.S(100, -20)
+    if got := s.Taper(eval.MaxPhase); got != 100 {
+        t.Errorf("Expected the middlegame value at full phase, got %d", got)
+    }
+    if got := s.Taper(0); got != -20 {
+        t.Errorf("Expected the endgame value at phase 0, got %d", got)
+    }
+    if got := s.Taper(eval.MaxPhase / 2); got != 40 {
+        t.Errorf("Expected the average halfway, got %d", got)
+    }
+}
+
+// The king should stay sheltered while the heavy pieces are on and head
+// for the center once they are gone.
+func TestKingCentralizesInEndgame(t *testing.T) {
+    scores := func(sheltered, central string) (int, int) {
+        a, err := board.FromFEN(sheltered)
+        if err != nil {
+            t.Fatal(err)
+        }
+        b, err := board.FromFEN(central)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return eval.Evaluate(a), eval.Evaluate(b)
+    }
+
+    sheltered, central := scores(
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQ1RK1 w kq - 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/4K3/PPPPPPPP/RNBQ1R2 w kq - 0 1",
+    )
+    if sheltered <= central {
+        t.Errorf("Expected Kg1 to beat Ke3 in the middlegame, got %d and %d", sheltered, central)
+    }
+
+    sheltered, central = scores(
+        "4k3/pppp4/8/8/8/8/PPPP4/6K1 w - - 0 1",
+        "4k3/pppp4/8/8/4K3/8/PPPP4/8 w - - 0 1",
+    )
+    if central <= sheltered {
+        t.Errorf("Expected Ke4 to beat Kg1 in a pawn ending, got %d and %d", central, sheltered)
+    }
+}
+
+// traceTerm returns White's and Black's values of a named trace term.
+func traceTerm(t *testing.T, fen, name string) (eval.Score, eval.Score) {
+    t.Helper()
+    b, err := board.FromFEN(fen)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for _, term := range eval.New(eval.DefaultWeights).Trace(b).Terms {
+        if term.Name == name {
+            return term.White, term.Black
+        }
+    }
+    t.Fatalf("No %q term in the trace", name)
+    return eval.Score{}, eval.Score{}
+}
+
+func TestPawnStructureTerms(t *testing.T) {
+    w := eval.DefaultWeights.Pawns
+    tests := []struct {
+        name  string
+        fen   string
+        term  string
+        white eval.Score
+        black eval.Score
+    }{
+        {"doubled", "4k3/8/8/8/8/4P3/4P3/4K3 w - - 0 1", "doubled pawns", w.Doubled, eval.Score{}},
+        {"isolated", "4k3/p7/8/8/8/4P3/4P3/4K3 w - - 0 1", "isolated pawns", w.Isolated.Mul(2), w.Isolated},
+        {"backward", "4k3/8/8/2p5/4P3/3P4/8/4K3 w - - 0 1", "backward pawns", w.Backward, eval.Score{}},
+        {"connected", "4k3/8/8/2p5/4P3/3P4/8/4K3 w - - 0 1", "connected pawns", w.Connected, eval.Score{}},
+        {"phalanx", "4k3/8/8/8/3PP3/8/8/4K3 w - - 0 1", "connected pawns", w.Connected.Mul(2), eval.Score{}},
+        {"passed", "4k3/8/8/8/3P4/8/5p2/K7 w - - 0 1", "passed pawns",
+            w.Passed[3].Add(w.FreePath[3]), w.Passed[6].Add(w.FreePath[6])},
+        {"passed but blocked", "3nk3/8/8/8/3P4/8/8/4K3 w - - 0 1", "passed pawns", w.Passed[3], eval.Score{}},
+        {"stopped by a neighbor", "4k3/4p3/8/8/3P4/8/8/4K3 w - - 0 1", "passed pawns", eval.Score{}, eval.Score{}},
+    }
+    for _, tt := range tests {
+        white, black := traceTerm(t, tt.fen, tt.term)
+        if white != tt.white || black != tt.black {
+            t.Errorf("%s: expected %s to be %s for White and %s for Black, got %s and %s",
+                tt.name, tt.term, tt.white, tt.black, white, black)
+        }
+    }
+}
+
+func TestPassedPawnBonusGrowsWithRank(t *testing.T) {
+    w := eval.DefaultWeights.Pawns
+    for rank := 2; rank < 7; rank++ {
+        if w.Passed[rank].EG <= w.Passed[rank-1].EG {
+            t.Errorf("Expected the passed pawn bonus on rank %d to beat rank %d", rank+1, rank)
+        }
+    }
+}
+
+func TestPawnHashTable(t *testing.T) {
+    e := eval.New(eval.DefaultWeights)
+    b := board.NewBoard()
+    first := e.Evaluate(b)
+
+    // A knight move leaves the pawns, and so the cached entry, unchanged
+    b.MovePiece(board.Position{Row: 0, Col: 6}, board.Position{Row: 2, Col: 5})
+    e.Evaluate(b)
+    hits, misses := e.PawnHashStats()
+    if hits != 1 || misses != 1 {
+        t.Errorf("Expected one hit and one miss, got %d and %d", hits, misses)
+    }
+    b.UnmakeMove()
+    if got := e.Evaluate(b); got != first {
+        t.Errorf("Expected the cached evaluation %d, got %d", first, got)
+    }
+
+    for _, fen := range evalPositions {
+        b, err := board.FromFEN(fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        e.Evaluate(b)
+        if got, want := e.Evaluate(b), e.Trace(b).Score; got != want {
+            t.Errorf("%s: expected the cached score to match the trace, got %d and %d", fen, got, want)
+        }
+    }
+}
+
+func TestKingSafetyTerms(t *testing.T) {
+    w := eval.DefaultWeights.KingSafety
+    tests := []struct {
+        name  string
+        fen   string
+        term  string
+        white eval.Score
+        black eval.Score
+    }{
+        {"full shield", "6k1/8/8/8/8/8/5PPP/6K1 w - - 0 1", "pawn shield",
+            w.ShieldNear.Mul(3), w.ShieldMissing.Mul(3)},
+        {"advanced shield", "6k1/8/8/8/8/6P1/5P1P/6K1 w - - 0 1", "pawn shield",
+            w.ShieldNear.Mul(2).Add(w.ShieldFar), w.ShieldMissing.Mul(3)},
+        {"storm", "6k1/8/8/8/8/6p1/5P1P/6K1 w - - 0 1", "pawn storm", w.Storm[2], eval.Score{}},
+        {"open and half-open files", "6k1/6p1/8/8/8/8/5P2/6K1 w - - 0 1", "king files",
+            w.SemiOpenFile.Add(w.OpenFile), w.SemiOpenFile.Add(w.OpenFile)},
+    }
+    for _, tt := range tests {
+        white, black := traceTerm(t, tt.fen, tt.term)
+        if white != tt.white || black != tt.black {
+            t.Errorf("%s: expected %s to be %s for White and %s for Black, got %s and %s",
+                tt.name, tt.term, tt.white, tt.black, white, black)
+        }
+    }
+}
+
+func TestKingAttack(t *testing.T) {
+    w := eval.DefaultWeights.KingSafety
+
+    // A lone knight is not enough for an attack
+    _, black := traceTerm(t, "6k1/5ppp/8/6N1/8/8/8/6K1 w - - 0 1", "king attack")
+    if black != (eval.Score{}) {
+        t.Errorf("Expected no attack from a single piece, got %s", black)
+    }
+
+    // The knight on g5 and the queen on h5 both hit f7 and h7
+    _, black = traceTerm(t, "6k1/5ppp/8/6NQ/8/8/8/6K1 w - - 0 1", "king attack")
+    units := 2*w.AttackUnits[board.Knight] + 2*w.AttackUnits[board.Queen]
+    if black != w.Danger[units] {
+        t.Errorf("Expected the danger for %d units, %s, got %s", units, w.Danger[units], black)
+    }
+
+    // A rook on the f-file joins in on f7
+    _, more := traceTerm(t, "6k1/5ppp/8/6NQ/8/8/8/5RK1 w - - 0 1", "king attack")
+    if more.MG >= black.MG {
+        t.Errorf("Expected a third attacker to raise the danger, got %s and %s", more, black)
+    }
+}
+
+// An exposed king should cost more than a sheltered one with the same
+// material.
+func TestShelteredKingBeatsExposedKing(t *testing.T) {
+    sheltered, err := board.FromFEN("r1bq1rk1/pppp1ppp/2n2n2/4p3/4P3/2N2N2/PPPP1PPP/R1BQ1RK1 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    exposed, err := board.FromFEN("r1bq1rk1/pppp1ppp/2n2n2/4p3/4P1P1/2N2N1P/PPPP1P2/R1BQ1RK1 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    e := eval.New(eval.DefaultWeights)
+    shield := func(b *board.Board) eval.Score {
+        for _, term := range e.Trace(b).Terms {
+            if term.Name == "pawn shield" {
+                return term.White
+            }
+        }
+        t.Fatal("No pawn shield term in the trace")
+        return eval.Score{}
+    }
+    if shield(sheltered).MG <= shield(exposed).MG {
+        t.Errorf("Expected the pawn shield of Kg1 behind f2, g2 and h2 to beat one with g4 and h3, got %s and %s",
+            shield(sheltered), shield(exposed))
+    }
+}
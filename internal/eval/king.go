@@ -0,0 +1,144 @@
+// internal/eval/king.go
+package eval
+
+import (
+    "math/bits"
+
+    "github.com/colmak/go-chess-go/pkg/board"
+)
+
+// KingSafetyWeights are the terms for an exposed king. The pawn terms look
+// at the king's file and the files beside it.
+type KingSafetyWeights struct {
+    ShieldNear    Score // An own pawn one rank in front of the king
+    ShieldFar     Score // The nearest own pawn two ranks in front of the king
+    ShieldMissing Score // No own pawn within two ranks in front of the king
+    // Storm is the penalty for the nearest enemy pawn in front of the king,
+    // indexed by how many ranks away it stands.
+    Storm        [8]Score
+    OpenFile     Score // No pawns on the file at all
+    SemiOpenFile Score // Enemy pawns on the file but none of our own
+    // AttackUnits counts each square around the king attacked by an enemy
+    // piece, indexed by board.PieceType. Piece types with no units are not
+    // counted towards MinAttackers.
+    AttackUnits [7]int
+    // MinAttackers is how many enemy pieces must attack the squares around
+    // the king before Danger applies.
+    MinAttackers int
+    // Danger is the penalty for the attack, indexed by its units (capped at
+    // 63).
+    Danger [64]Score
+}
+
+var defaultKingSafetyWeights = KingSafetyWeights{
+    ShieldNear:    S(15, 0),
+    ShieldFar:     S(8, 0),
+    ShieldMissing: S(-15, 0),
+    Storm:         [8]Score{1: S(-5, 0), 2: S(-30, 0), 3: S(-15, 0), 4: S(-5, 0)},
+    OpenFile:      S(-25, 0),
+    SemiOpenFile:  S(-12, 0),
+    AttackUnits: [7]int{
+        board.Knight: 2,
+        board.Bishop: 2,
+        board.Rook:   3,
+        board.Queen:  5,
+    },
+    MinAttackers: 2,
+    Danger:       dangerTable(),
+}
+
+// dangerTable grows quadratically with the attack units, so that several
+// pieces joining an attack count for more than the sum of each alone.
+func dangerTable() [64]Score {
+    var table [64]Score
+    for units := range table {
+        mg := min(units*units/2, 600)
+        table[units] = S(-mg, -mg/4)
+    }
+    return table
+}
+
+// The king safety terms, in the order they appear in a Trace.
+const (
+    shieldTerm = iota
+    stormTerm
+    kingFilesTerm
+    kingAttackTerm
+    numKingTerms
+)
+
+var kingTermNames = [numKingTerms]string{"pawn shield", "pawn storm", "king files", "king attack"}
+
+// evaluateKingSafety scores how exposed each side's king is, indexed by
+// color (0 for White).
+func evaluateKingSafety(p *board.Bitboards, w *KingSafetyWeights) [numKingTerms][2]Score {
+    var terms [numKingTerms][2]Score
+    pawns := [2]uint64{uint64(p.Pieces[0][board.Pawn]), uint64(p.Pieces[1][board.Pawn])}
+    for side := 0; side < 2; side++ {
+        kings := uint64(p.Pieces[side][board.King])
+        if kings == 0 {
+            continue
+        }
+        king := bits.TrailingZeros64(kings)
+        row, col := king/8, king%8
+        own, enemy := pawns[side], pawns[1-side]
+
+        for file := max(col-1, 0); file <= min(col+1, 7); file++ {
+            ahead := fileMasks[file] & aheadRanks[side][row]
+            switch nearestPawn(own&ahead, side, row) {
+            case 1:
+                terms[shieldTerm][side] = terms[shieldTerm][side].Add(w.ShieldNear)
+            case 2:
+                terms[shieldTerm][side] = terms[shieldTerm][side].Add(w.ShieldFar)
+            default:
+                terms[shieldTerm][side] = terms[shieldTerm][side].Add(w.ShieldMissing)
+            }
+
+            if distance := nearestPawn(enemy&ahead, side, row); distance > 0 {
+                terms[stormTerm][side] = terms[stormTerm][side].Add(w.Storm[distance])
+            }
+
+            if own&fileMasks[file] == 0 {
+                if enemy&fileMasks[file] == 0 {
+                    terms[kingFilesTerm][side] = terms[kingFilesTerm][side].Add(w.OpenFile)
+                } else {
+                    terms[kingFilesTerm][side] = terms[kingFilesTerm][side].Add(w.SemiOpenFile)
+                }
+            }
+        }
+
+        // The king zone is the king's square and every square next to it
+        zone := uint64(p.Attacks(board.Square(king))) | kings
+        attackers, units := 0, 0
+        for pieceType := board.Rook; pieceType <= board.Pawn; pieceType++ {
+            // Pieces that earn no units do not count as attackers either
+            if w.AttackUnits[pieceType] == 0 {
+                continue
+            }
+            for set := uint64(p.Pieces[1-side][pieceType]); set != 0; set &= set - 1 {
+                attacked := uint64(p.Attacks(board.Square(bits.TrailingZeros64(set)))) & zone
+                if attacked != 0 {
+                    attackers++
+                    units += w.AttackUnits[pieceType] * bits.OnesCount64(attacked)
+                }
+            }
+        }
+        if attackers >= w.MinAttackers {
+            terms[kingAttackTerm][side] = w.Danger[min(units, 63)]
+        }
+    }
+    return terms
+}
+
+// nearestPawn returns how many ranks in front of row the nearest of pawns
+// stands for side, or 0 if there are none. All of pawns must be in front of
+// row.
+func nearestPawn(pawns uint64, side, row int) int {
+    if pawns == 0 {
+        return 0
+    }
+    if side == 0 {
+        return bits.TrailingZeros64(pawns)/8 - row
+    }
+    return row - (63-bits.LeadingZeros64(pawns))/8
+}
@@ -0,0 +1,189 @@
+// internal/eval/pawns.go
+package eval
+
+import "math/bits"
+
+// PawnWeights are the pawn structure terms. Each penalty or bonus applies
+// once per pawn.
+type PawnWeights struct {
+    Doubled   Score // A pawn with another pawn of its side in front of it
+    Isolated  Score // No pawns of its side on the neighboring files
+    Backward  Score // Behind its neighbors, with its stop square guarded by an enemy pawn
+    Connected Score // Defended by a pawn or standing beside one
+    // Passed is the bonus for a pawn no enemy pawn can stop, indexed by
+    // its rank counted from its own side (1 to 6).
+    Passed [8]Score
+    // FreePath is added to Passed when nothing stands between the pawn and
+    // its promotion square.
+    FreePath [8]Score
+}
+
+var defaultPawnWeights = PawnWeights{
+    Doubled:   S(-10, -20),
+    Isolated:  S(-10, -15),
+    Backward:  S(-8, -10),
+    Connected: S(8, 6),
+    Passed:    [8]Score{1: S(5, 10), 2: S(10, 15), 3: S(15, 25), 4: S(30, 50), 5: S(50, 90), 6: S(80, 140)},
+    FreePath:  [8]Score{1: S(0, 5), 2: S(0, 5), 3: S(5, 10), 4: S(10, 20), 5: S(15, 35), 6: S(25, 60)},
+}
+
+// The pawn structure terms, in the order they appear in a Trace.
+const (
+    doubledTerm = iota
+    isolatedTerm
+    backwardTerm
+    connectedTerm
+    passedTerm
+    numPawnTerms
+)
+
+var pawnTermNames = [numPawnTerms]string{"doubled pawns", "isolated pawns", "backward pawns", "connected pawns", "passed pawns"}
+
+// pawnEntry is the pawn structure evaluation of one placement of pawns. The
+// free path bonus depends on the other pieces too, so only the passed pawns
+// are kept for it.
+type pawnEntry struct {
+    key    uint64
+    terms  [numPawnTerms][2]Score
+    passed [2]uint64
+}
+
+// pawnHashSize is the number of entries in an Evaluator's pawn hash table.
+const pawnHashSize = 1 << 14
+
+// pawnHashTable caches pawn structure evaluations by board.Board.PawnHash.
+type pawnHashTable struct {
+    entries []pawnEntry
+    hits    uint64
+    misses  uint64
+}
+
+func newPawnHashTable() *pawnHashTable {
+    return &pawnHashTable{entries: make([]pawnEntry, pawnHashSize)}
+}
+
+// probe returns the entry for key, evaluating pawns into it on a miss.
+func (t *pawnHashTable) probe(key uint64, pawns [2]uint64, w *PawnWeights) *pawnEntry {
+    entry := &t.entries[key%pawnHashSize]
+    // The key of an empty slot is 0, which is also the key of a board with
+    // no pawns; evaluating that again is cheap
+    if entry.key == key && key != 0 {
+        t.hits++
+        return entry
+    }
+    t.misses++
+    *entry = evaluatePawns(pawns, w)
+    entry.key = key
+    return entry
+}
+
+// Masks for the pawn structure, on the squares of board.Square.
+var (
+    fileMasks     [8]uint64
+    adjacentFiles [8]uint64
+    // aheadRanks holds, per side and rank, the ranks in front of a pawn of
+    // that side
+    aheadRanks [2][8]uint64
+)
+
+func init() {
+    for col := 0; col < 8; col++ {
+        fileMasks[col] = 0x0101010101010101 << uint(col)
+    }
+    for col := 0; col < 8; col++ {
+        if col > 0 {
+            adjacentFiles[col] |= fileMasks[col-1]
+        }
+        if col < 7 {
+            adjacentFiles[col] |= fileMasks[col+1]
+        }
+    }
+    for row := 0; row < 8; row++ {
+        aheadRanks[0][row] = ^uint64(0) << uint(8*(row+1))
+        aheadRanks[1][row] = ^uint64(0) >> uint(8*(8-row))
+    }
+}
+
+func bit(row, col int) uint64 {
+    if row < 0 || row > 7 || col < 0 || col > 7 {
+        return 0
+    }
+    return 1 << uint(row*8+col)
+}
+
+// evaluatePawns scores the pawn structure given each side's pawns, indexed
+// by color (0 for White).
+func evaluatePawns(pawns [2]uint64, w *PawnWeights) pawnEntry {
+    var entry pawnEntry
+    for side := 0; side < 2; side++ {
+        own, enemy := pawns[side], pawns[1-side]
+        forward := 1
+        if side == 1 {
+            forward = -1
+        }
+
+        for set := own; set != 0; set &= set - 1 {
+            sq := bits.TrailingZeros64(set)
+            row, col := sq/8, sq%8
+            ahead := aheadRanks[side][row]
+            terms := &entry.terms
+
+            if own&fileMasks[col]&ahead != 0 {
+                terms[doubledTerm][side] = terms[doubledTerm][side].Add(w.Doubled)
+            }
+
+            neighbors := own & adjacentFiles[col]
+            if neighbors == 0 {
+                terms[isolatedTerm][side] = terms[isolatedTerm][side].Add(w.Isolated)
+            } else if neighbors&^ahead == 0 {
+                // Every neighbor has advanced past it, so none can defend
+                // its stop square
+                stop := row + forward
+                if enemy&(bit(stop+forward, col-1)|bit(stop+forward, col+1)) != 0 {
+                    terms[backwardTerm][side] = terms[backwardTerm][side].Add(w.Backward)
+                }
+            }
+
+            supported := bit(row-forward, col-1) | bit(row-forward, col+1)
+            phalanx := bit(row, col-1) | bit(row, col+1)
+            if own&(supported|phalanx) != 0 {
+                terms[connectedTerm][side] = terms[connectedTerm][side].Add(w.Connected)
+            }
+
+            front := (fileMasks[col] | adjacentFiles[col]) & ahead
+            if enemy&front == 0 && own&fileMasks[col]&ahead == 0 {
+                entry.passed[side] |= 1 << uint(sq)
+                terms[passedTerm][side] = terms[passedTerm][side].Add(w.Passed[relativeRank(side, row)])
+            }
+        }
+    }
+    return entry
+}
+
+// freePathBonus adds FreePath for each passed pawn of side with nothing on
+// the squares in front of it.
+func freePathBonus(passed uint64, side int, occupied uint64, w *PawnWeights) Score {
+    var s Score
+    for set := passed; set != 0; set &= set - 1 {
+        sq := bits.TrailingZeros64(set)
+        row, col := sq/8, sq%8
+        if occupied&fileMasks[col]&aheadRanks[side][row] == 0 {
+            s = s.Add(w.FreePath[relativeRank(side, row)])
+        }
+    }
+    return s
+}
+
+// relativeRank returns row counted from side's first rank.
+func relativeRank(side, row int) int {
+    if side == 1 {
+        return 7 - row
+    }
+    return row
+}
+
+// PawnHashStats reports how often the pawn hash table has been hit and
+// missed.
+func (e *Evaluator) PawnHashStats() (hits, misses uint64) {
+    return e.pawns.hits, e.pawns.misses
+}
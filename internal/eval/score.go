@@ -0,0 +1,46 @@
+// internal/eval/score.go
+package eval
+
+import "fmt"
+
+// MaxPhase is the game phase of the starting material. Evaluator.Phase
+// scales the phase weights of the pieces on the board so that the starting
+// material reaches MaxPhase whatever the weights are.
+const MaxPhase = 24
+
+// Score is a pair of values in centipawns, one for the middlegame and one
+// for the endgame. Taper blends them by the game phase.
+type Score struct {
+    MG int
+    EG int
+}
+
+// S returns the Score with middlegame value mg and endgame value eg.
+func S(mg, eg int) Score {
+    return Score{mg, eg}
+}
+
+func (s Score) Add(o Score) Score {
+    return Score{s.MG + o.MG, s.EG + o.EG}
+}
+
+func (s Score) Sub(o Score) Score {
+    return Score{s.MG - o.MG, s.EG - o.EG}
+}
+
+// Mul scales both values by n.
+func (s Score) Mul(n int) Score {
+    return Score{s.MG * n, s.EG * n}
+}
+
+// Taper interpolates between the endgame value at phase 0 and the
+// middlegame value at MaxPhase.
+func (s Score) Taper(phase int) int {
+    phase = max(0, min(phase, MaxPhase))
+    return (s.MG*phase + s.EG*(MaxPhase-phase)) / MaxPhase
+}
+
+// String formats the score as "mg/eg".
+func (s Score) String() string {
+    return fmt.Sprintf("%d/%d", s.MG, s.EG)
+}
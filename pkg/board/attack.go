@@ -0,0 +1,31 @@
+package board
+
+// IsSquareAttacked reports whether any piece of byColor (White or Black)
+// attacks pos. The piece standing on pos, if any, is ignored.
+func (b *Board) IsSquareAttacked(pos Position, byColor Color) bool {
+    if !isWithinBounds(pos) {
+        return false
+    }
+    p := b.pieceBitboards()
+    return p.attackers(pos.Square(), colorIndex(byColor), p.Occupied) != 0
+}
+
+// AttackersOf returns the positions of every piece, of either color, that
+// attacks pos. Sliding pieces are blocked by the first piece on their ray.
+func (b *Board) AttackersOf(pos Position) []Position {
+    if !isWithinBounds(pos) {
+        return nil
+    }
+    p := b.pieceBitboards()
+    sq := pos.Square()
+    return (p.attackers(sq, 0, p.Occupied) | p.attackers(sq, 1, p.Occupied)).Positions()
+}
+
+// Attacks returns the squares attacked by the piece on pos, as
+// Bitboards.Attacks.
+func (b *Board) Attacks(pos Position) Bitboard {
+    if !isWithinBounds(pos) {
+        return 0
+    }
+    return b.pieceBitboards().Attacks(pos.Square())
+}
@@ -0,0 +1,135 @@
+package board
+
+import "math/bits"
+
+// Bitboard is a set of squares with one bit per square: a1 is bit 0, b1 is
+// bit 1 and h8 is bit 63.
+type Bitboard uint64
+
+// Ray directions used to index the sliding attack tables. The first four
+// move towards higher square indexes.
+const (
+    north = iota
+    east
+    northEast
+    northWest
+    south
+    west
+    southEast
+    southWest
+)
+
+var (
+    knightAttacks [64]Bitboard
+    kingAttacks   [64]Bitboard
+    pawnAttacks   [2][64]Bitboard // Indexed by color: 0 for White, 1 for Black
+    rays          [8][64]Bitboard // Squares reached from a square on an empty board
+
+    rayDirections = [8][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}, {-1, 0}, {0, -1}, {-1, 1}, {-1, -1}}
+)
+
+func init() {
+    for sq := Square(0); sq < 64; sq++ {
+        pos := sq.Position()
+        for _, offset := range knightOffsets {
+            knightAttacks[sq] |= bitAt(Position{pos.Row + offset[0], pos.Col + offset[1]})
+        }
+        for _, offset := range kingOffsets {
+            kingAttacks[sq] |= bitAt(Position{pos.Row + offset[0], pos.Col + offset[1]})
+        }
+        for _, dc := range [2]int{-1, 1} {
+            pawnAttacks[0][sq] |= bitAt(Position{pos.Row + 1, pos.Col + dc})
+            pawnAttacks[1][sq] |= bitAt(Position{pos.Row - 1, pos.Col + dc})
+        }
+        for dir, step := range rayDirections {
+            p := Position{pos.Row + step[0], pos.Col + step[1]}
+            for isWithinBounds(p) {
+                rays[dir][sq] |= bitAt(p)
+                p = Position{p.Row + step[0], p.Col + step[1]}
+            }
+        }
+    }
+}
+
+// BitboardOf returns the bitboard holding the single square pos.
+func BitboardOf(pos Position) Bitboard {
+    return bitAt(pos)
+}
+
+// Has reports whether pos is in the set.
+func (bb Bitboard) Has(pos Position) bool {
+    return isWithinBounds(pos) && bb&bitAt(pos) != 0
+}
+
+// Count returns the number of squares in the set.
+func (bb Bitboard) Count() int {
+    return bits.OnesCount64(uint64(bb))
+}
+
+// Positions returns the squares in the set, from a1 to h8.
+func (bb Bitboard) Positions() []Position {
+    positions := make([]Position, 0, bb.Count())
+    for bb != 0 {
+        positions = append(positions, bb.popLSB().Position())
+    }
+    return positions
+}
+
+// lsb returns the lowest square in a non-empty set.
+func (bb Bitboard) lsb() Square {
+    return Square(bits.TrailingZeros64(uint64(bb)))
+}
+
+// msb returns the highest square in a non-empty set.
+func (bb Bitboard) msb() Square {
+    return Square(63 - bits.LeadingZeros64(uint64(bb)))
+}
+
+// popLSB removes the lowest square from the set and returns it.
+func (bb *Bitboard) popLSB() Square {
+    sq := bb.lsb()
+    *bb &= *bb - 1
+    return sq
+}
+
+// rayAttacks returns the squares a slider on sq attacks in one direction,
+// up to and including the first occupied square.
+func rayAttacks(dir int, sq Square, occupied Bitboard) Bitboard {
+    attacks := rays[dir][sq]
+    if blockers := attacks & occupied; blockers != 0 {
+        if dir < south {
+            attacks ^= rays[dir][blockers.lsb()]
+        } else {
+            attacks ^= rays[dir][blockers.msb()]
+        }
+    }
+    return attacks
+}
+
+func rookAttacks(sq Square, occupied Bitboard) Bitboard {
+    return rayAttacks(north, sq, occupied) | rayAttacks(east, sq, occupied) |
+        rayAttacks(south, sq, occupied) | rayAttacks(west, sq, occupied)
+}
+
+func bishopAttacks(sq Square, occupied Bitboard) Bitboard {
+    return rayAttacks(northEast, sq, occupied) | rayAttacks(northWest, sq, occupied) |
+        rayAttacks(southEast, sq, occupied) | rayAttacks(southWest, sq, occupied)
+}
+
+// rankSpan returns the squares from a to b inclusive, which must lie on the
+// same rank.
+func rankSpan(a, b Square) Bitboard {
+    if a > b {
+        a, b = b, a
+    }
+    return (Bitboard(1)<<uint(b-a+1) - 1) << uint(a)
+}
+
+// bitAt returns the single-square bitboard of pos, or an empty set if pos is
+// off the board.
+func bitAt(pos Position) Bitboard {
+    if !isWithinBounds(pos) {
+        return 0
+    }
+    return 1 << uint(pos.Square())
+}
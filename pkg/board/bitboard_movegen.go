@@ -0,0 +1,389 @@
+package board
+
+// Bitboards is a bitboard representation of a position: one set of squares
+// per color and piece type. Every Board keeps its pieces as bitboards next to
+// the 8x8 Squares layout and answers attack and check queries from them;
+// Perft runs entirely on a copy.
+type Bitboards struct {
+    Pieces    [2][7]Bitboard // Indexed by color (0 for White, 1 for Black) and piece type
+    Colors    [2]Bitboard
+    Occupied  Bitboard
+    Turn      Color
+    Castling  CastlingRights
+    EnPassant Square // En passant square, or NoSquare
+    Chess960  bool
+
+    FiftyMoveCount int
+    MoveCount      int
+
+    rooks castlingRooks
+}
+
+// bbMove is a move between two squares, with the promotion piece type.
+// Castling is always written as the king taking its own rook.
+type bbMove struct {
+    from, to  Square
+    promotion PieceType
+    castling  bool
+}
+
+// Bitboards returns a copy of the board in its bitboard representation.
+func (b *Board) Bitboards() *Bitboards {
+    p := *b.pieceBitboards()
+    p.Turn = b.CurrentTurn
+    p.Castling = b.Castling
+    p.EnPassant = b.EnPassant.Square()
+    p.Chess960 = b.Chess960
+    p.FiftyMoveCount = b.FiftyMoveCount
+    p.MoveCount = b.MoveCount
+    p.rooks = b.rooks()
+    return &p
+}
+
+// pieceBitboards returns the board's bitboards, first bringing them and the
+// hashes up to date if Squares has been written to directly.
+func (b *Board) pieceBitboards() *Bitboards {
+    b.sync()
+    return &b.bitboards
+}
+
+// sync rebuilds the bitboards and hashes if Squares has changed behind
+// SetPieceAt's back.
+func (b *Board) sync() {
+    if b.Squares == b.synced {
+        return
+    }
+    b.syncBitboards()
+    b.hash = b.computeHash()
+    b.pawnHash = b.computePawnHash()
+}
+
+// syncBitboards rebuilds the board's bitboards after Squares has been filled
+// in directly.
+func (b *Board) syncBitboards() {
+    b.bitboards = Bitboards{}
+    b.synced = b.Squares
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            if piece := b.Squares[row][col]; piece != NoPiece {
+                b.bitboards.toggle(piece, bitAt(Position{row, col}))
+            }
+        }
+    }
+}
+
+// toggle places piece on the squares of set, or lifts it from them if it is
+// already there.
+func (p *Bitboards) toggle(piece Piece, set Bitboard) {
+    color := colorIndex(piece.Color())
+    p.Pieces[color][piece.Type()] ^= set
+    p.Colors[color] ^= set
+    p.Occupied ^= set
+}
+
+// Board converts the bitboards back to the 8x8 layout. The new board has no
+// move history.
+func (p *Bitboards) Board() *Board {
+    b := &Board{
+        CurrentTurn:    p.Turn,
+        Castling:       p.Castling,
+        EnPassant:      p.EnPassant.Position(),
+        Chess960:       p.Chess960,
+        FiftyMoveCount: p.FiftyMoveCount,
+        HalfMoveClock:  p.FiftyMoveCount,
+        MoveCount:      p.MoveCount,
+        rookFiles:      p.rooks,
+    }
+
+    for color, pieceColor := range [2]Color{White, Black} {
+        for pieceType := Rook; pieceType <= Pawn; pieceType++ {
+            set := p.Pieces[color][pieceType]
+            for set != 0 {
+                pos := set.popLSB().Position()
+                b.Squares[pos.Row][pos.Col] = NewPiece(pieceType, pieceColor)
+            }
+        }
+    }
+    b.syncBitboards()
+    b.hash = b.computeHash()
+    b.pawnHash = b.computePawnHash()
+    return b
+}
+
+// Perft counts leaf nodes to the given depth, like Board.Perft.
+func (p *Bitboards) Perft(depth int) uint64 {
+    if depth <= 0 {
+        return 1
+    }
+
+    var moves [256]bbMove
+    var nodes uint64
+    // The recursive call makes next escape, so reuse one copy per node
+    var next Bitboards
+    for _, move := range p.generate(moves[:0]) {
+        next = *p
+        next.makeMove(move)
+        if next.inCheck(p.Turn) {
+            continue
+        }
+        if depth == 1 {
+            nodes++
+        } else {
+            nodes += next.Perft(depth - 1)
+        }
+    }
+    return nodes
+}
+
+// pieceAt returns the piece on sq, or NoPiece.
+func (p *Bitboards) pieceAt(sq Square) Piece {
+    bit := Bitboard(1) << uint(sq)
+    for color, pieceColor := range [2]Color{White, Black} {
+        if p.Colors[color]&bit == 0 {
+            continue
+        }
+        for pieceType := Rook; pieceType <= Pawn; pieceType++ {
+            if p.Pieces[color][pieceType]&bit != 0 {
+                return NewPiece(pieceType, pieceColor)
+            }
+        }
+    }
+    return NoPiece
+}
+
+// attackers returns the pieces of the given color index attacking sq.
+func (p *Bitboards) attackers(sq Square, by int, occupied Bitboard) Bitboard {
+    pieces := &p.Pieces[by]
+    queens := pieces[Queen]
+    return pawnAttacks[1-by][sq]&pieces[Pawn] |
+        knightAttacks[sq]&pieces[Knight] |
+        kingAttacks[sq]&pieces[King] |
+        bishopAttacks(sq, occupied)&(pieces[Bishop]|queens) |
+        rookAttacks(sq, occupied)&(pieces[Rook]|queens)
+}
+
+// Attacks returns the squares attacked by the piece on sq, with sliding
+// pieces stopped by the first piece in their way, or an empty set if sq is
+// empty. Pawns attack the two squares diagonally in front of them.
+func (p *Bitboards) Attacks(sq Square) Bitboard {
+    piece := p.pieceAt(sq)
+    switch piece.Type() {
+    case Pawn:
+        return pawnAttacks[colorIndex(piece.Color())][sq]
+    case Knight:
+        return knightAttacks[sq]
+    case Bishop:
+        return bishopAttacks(sq, p.Occupied)
+    case Rook:
+        return rookAttacks(sq, p.Occupied)
+    case Queen:
+        return bishopAttacks(sq, p.Occupied) | rookAttacks(sq, p.Occupied)
+    case King:
+        return kingAttacks[sq]
+    }
+    return 0
+}
+
+// AttackersTo returns the pieces of both colors attacking sq when only the
+// squares in occupied block sliding pieces. Passing fewer squares than
+// Occupied reveals x-ray attackers behind the removed pieces; pieces that are
+// not in occupied are left out of the result.
+func (p *Bitboards) AttackersTo(sq Square, occupied Bitboard) Bitboard {
+    return (p.attackers(sq, 0, occupied) | p.attackers(sq, 1, occupied)) & occupied
+}
+
+// inCheck reports whether the king of color (White or Black) is attacked.
+func (p *Bitboards) inCheck(color Color) bool {
+    us := colorIndex(color)
+    king := p.Pieces[us][King]
+    if king == 0 {
+        return false
+    }
+    return p.attackers(king.lsb(), 1-us, p.Occupied) != 0
+}
+
+// generate appends the pseudo-legal moves of the side to move to moves.
+// Castling is only generated when it is fully legal.
+func (p *Bitboards) generate(moves []bbMove) []bbMove {
+    us := colorIndex(p.Turn)
+    them := 1 - us
+    own, enemy := p.Colors[us], p.Colors[them]
+    empty := ^p.Occupied
+
+    // Pawns
+    var forward Square = 8
+    startRank, lastRank := 1, 7
+    if us == 1 {
+        forward, startRank, lastRank = -8, 6, 0
+    }
+    addPawnMove := func(from, to Square) {
+        if int(to)/8 == lastRank {
+            for _, promotion := range promotionPieces {
+                moves = append(moves, bbMove{from: from, to: to, promotion: promotion})
+            }
+            return
+        }
+        moves = append(moves, bbMove{from: from, to: to})
+    }
+    pawns := p.Pieces[us][Pawn]
+    for pawns != 0 {
+        from := pawns.popLSB()
+        to := from + forward
+        if empty&(1<<uint(to)) != 0 {
+            addPawnMove(from, to)
+            if int(from)/8 == startRank && empty&(1<<uint(to+forward)) != 0 {
+                moves = append(moves, bbMove{from: from, to: to + forward})
+            }
+        }
+        targets := pawnAttacks[us][from] & enemy
+        if p.EnPassant != NoSquare {
+            targets |= pawnAttacks[us][from] & (1 << uint(p.EnPassant))
+        }
+        for targets != 0 {
+            addPawnMove(from, targets.popLSB())
+        }
+    }
+
+    // Pieces
+    for pieceType := Rook; pieceType <= King; pieceType++ {
+        pieces := p.Pieces[us][pieceType]
+        for pieces != 0 {
+            from := pieces.popLSB()
+            var attacks Bitboard
+            switch pieceType {
+            case Knight:
+                attacks = knightAttacks[from]
+            case Bishop:
+                attacks = bishopAttacks(from, p.Occupied)
+            case Rook:
+                attacks = rookAttacks(from, p.Occupied)
+            case Queen:
+                attacks = bishopAttacks(from, p.Occupied) | rookAttacks(from, p.Occupied)
+            case King:
+                attacks = kingAttacks[from]
+            }
+            attacks &^= own
+            for attacks != 0 {
+                moves = append(moves, bbMove{from: from, to: attacks.popLSB()})
+            }
+        }
+    }
+
+    return p.castlingMoves(moves)
+}
+
+func (p *Bitboards) castlingMoves(moves []bbMove) []bbMove {
+    us := colorIndex(p.Turn)
+    isBlack := us == 1
+    row := homeRow(isBlack)
+    kings := p.Pieces[us][King]
+    if kings == 0 || kings.lsb().Position().Row != row {
+        return moves
+    }
+    king := kings.lsb()
+
+    for side, kingside := range [2]bool{true, false} {
+        if !p.Castling.Has(castlingRight(us, side)) {
+            continue
+        }
+        c := castlingFor(p.rooks, king.Position(), isBlack, kingside)
+        rook := c.rookFrom.Square()
+        if p.Pieces[us][Rook]&(1<<uint(rook)) == 0 {
+            continue
+        }
+
+        from, to := c.span()
+        others := p.Occupied &^ (1<<uint(king) | 1<<uint(rook))
+        if others&rankSpan(Position{row, from}.Square(), Position{row, to}.Square()) != 0 {
+            continue
+        }
+
+        if p.castlingSafe(c, us) {
+            moves = append(moves, bbMove{from: king, to: rook, castling: true})
+        }
+    }
+    return moves
+}
+
+// castlingSafe reports whether no enemy piece attacks a square the king
+// crosses, from its start square to its destination. The castling rook is
+// lifted first: in Chess960 it may stand between an enemy rook and the
+// king's destination and would no longer shield it after castling.
+func (p *Bitboards) castlingSafe(c castling, us int) bool {
+    occupied := p.Occupied &^ bitAt(c.rookFrom)
+    path := rankSpan(c.kingFrom.Square(), c.kingTo.Square())
+    for path != 0 {
+        if p.attackers(path.popLSB(), 1-us, occupied) != 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// makeMove plays a pseudo-legal move in place.
+func (p *Bitboards) makeMove(move bbMove) {
+    us := colorIndex(p.Turn)
+    them := 1 - us
+    fromBit := Bitboard(1) << uint(move.from)
+    toBit := Bitboard(1) << uint(move.to)
+    piece := p.pieceAt(move.from).Type()
+
+    if move.castling {
+        c := castlingFor(p.rooks, move.from.Position(), us == 1, move.to > move.from)
+        kingBits := Bitboard(1)<<uint(move.from) ^ Bitboard(1)<<uint(c.kingTo.Square())
+        rookBits := Bitboard(1)<<uint(move.to) ^ Bitboard(1)<<uint(c.rookTo.Square())
+        p.Pieces[us][King] ^= kingBits
+        p.Pieces[us][Rook] ^= rookBits
+        p.Colors[us] ^= kingBits ^ rookBits
+        p.Castling &^= castlingRight(us, 0) | castlingRight(us, 1)
+        p.EnPassant = NoSquare
+        p.FiftyMoveCount++
+        p.Occupied = p.Colors[0] | p.Colors[1]
+        p.MoveCount++
+        p.Turn = p.Turn.Opponent()
+        return
+    }
+
+    p.FiftyMoveCount++
+    if p.Colors[them]&toBit != 0 {
+        captured := p.pieceAt(move.to).Type()
+        p.Pieces[them][captured] ^= toBit
+        p.Colors[them] ^= toBit
+        p.FiftyMoveCount = 0
+    }
+
+    p.Pieces[us][piece] ^= fromBit | toBit
+    p.Colors[us] ^= fromBit | toBit
+
+    switch piece {
+    case Pawn:
+        p.FiftyMoveCount = 0
+        if move.to == p.EnPassant {
+            capturedBit := Bitboard(1) << uint(move.to-8+16*Square(us))
+            p.Pieces[them][Pawn] ^= capturedBit
+            p.Colors[them] ^= capturedBit
+        }
+        if move.promotion != NoPieceType {
+            p.Pieces[us][Pawn] ^= toBit
+            p.Pieces[us][move.promotion] ^= toBit
+        }
+    }
+
+    p.Castling &^= p.rooks.lost(NewPiece(piece, p.Turn), move.from.Position(), move.to.Position())
+    p.EnPassant = NoSquare
+    if piece == Pawn && (move.to-move.from == 16 || move.from-move.to == 16) {
+        p.EnPassant = (move.from + move.to) / 2
+    }
+
+    p.Occupied = p.Colors[0] | p.Colors[1]
+    p.MoveCount++
+    p.Turn = p.Turn.Opponent()
+}
+
+// colorIndex returns 0 for White and 1 for Black.
+func colorIndex(color Color) int {
+    if color == Black {
+        return 1
+    }
+    return 0
+}
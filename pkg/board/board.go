@@ -3,17 +3,24 @@ package board
 import "fmt"
 
 type Board struct {
-    Squares [8][8]int 
-    WhiteKingMoved bool
-    BlackKingMoved bool
-    WhiteRookMoved [2]bool
-    BlackRookMoved [2]bool
-    CurrentTurn int     
+    Squares [8][8]Piece // Direct writes are picked up the next time the bitboards or hashes are read
+    Castling CastlingRights
+    Chess960 bool // Castling rooks may start on any file; castling is written as the king taking its own rook
+    EnPassant Position // Square a pawn may capture en passant onto, or NoPosition
+    CurrentTurn Color
+    // Deprecated: HalfMoveClock mirrors FiftyMoveCount, which is the one FEN
+    // and the draw rules read. Use FiftyMoveCount instead.
     HalfMoveClock int
     MoveCount int  
-    FiftyMoveCount int
-    PositionHistory map[string]int 
+    FiftyMoveCount int // Halfmove clock: plies since the last capture or pawn move, as in FEN
     LastMove Move
+    history []undoState // Undo information for every move made, most recent last
+    hash uint64 // Zobrist hash of the position
+    pawnHash uint64 // Zobrist hash of the pawns alone
+    ending Outcome // Set by Resign, Timeout, AgreeDraw and ClaimDraw
+    rookFiles castlingRooks // Castling rook files when playing Chess960
+    bitboards Bitboards // The pieces of Squares as bitboards; only Pieces, Colors and Occupied are kept
+    synced [8][8]Piece // Squares as bitboards and the hashes last saw it
 }
 
 type Position struct {
@@ -21,74 +28,61 @@ type Position struct {
     Col int
 }
 
-type Move struct {
-    Start Position
-    End   Position
-    Piece int
-}
-
-func (b *Board) isPathClear(start, end Position) bool {
-    rowStep := sign(end.Row - start.Row)
-    colStep := sign(end.Col - start.Col)
-    
-    currentRow := start.Row + rowStep
-    currentCol := start.Col + colStep
-
-    for currentRow != end.Row || currentCol != end.Col {
-        if !b.IsEmpty(Position{currentRow, currentCol}) {
-            return false
-        }
-        currentRow += rowStep
-        currentCol += colStep
-    }
+// NoPosition marks the absence of a square, such as when no en passant
+// capture is possible.
+var NoPosition = Position{-1, -1}
 
-    return true
+type Move struct {
+    Start     Position
+    End       Position
+    Piece     Piece
+    Promotion PieceType // Piece type a pawn promotes to, or NoPieceType
 }
 
+// PrintBoard writes the board to standard output with White at the bottom.
+// Use Render for other layouts.
 func (b *Board) PrintBoard() {
-    for i := 0; i < 8; i++ {
-        for j := 0; j < 8; j++ {
-            fmt.Printf("%d ", b.Squares[i][j])
-        }
-        fmt.Println()
-    }
+    fmt.Print(b.Render(RenderOptions{Coordinates: true}))
 }
 
 func NewBoard() *Board {
     b := &Board{
-        CurrentTurn:     White,
-        HalfMoveClock:   0,
-        PositionHistory: make(map[string]int),
+        CurrentTurn: White,
+        Castling:    AllCastling,
+        EnPassant:   NoPosition,
     }
-    b.initPosition() 
+    b.initPosition()
+    b.syncBitboards()
+    b.hash = b.computeHash()
+    b.pawnHash = b.computePawnHash()
     return b
 }
 
-func (b *Board) GetCurrentTurn() int {
+func (b *Board) GetCurrentTurn() Color {
     return b.CurrentTurn
 }
 
 func (b *Board) initPosition() {
-    b.Squares[0][0] = Rook | White
-    b.Squares[0][1] = Knight | White
-    b.Squares[0][2] = Bishop | White
-    b.Squares[0][3] = Queen | White
-    b.Squares[0][4] = King | White
-    b.Squares[0][5] = Bishop | White
-    b.Squares[0][6] = Knight | White
-    b.Squares[0][7] = Rook | White
+    b.Squares[0][0] = WhiteRook
+    b.Squares[0][1] = WhiteKnight
+    b.Squares[0][2] = WhiteBishop
+    b.Squares[0][3] = WhiteQueen
+    b.Squares[0][4] = WhiteKing
+    b.Squares[0][5] = WhiteBishop
+    b.Squares[0][6] = WhiteKnight
+    b.Squares[0][7] = WhiteRook
     for i := 0; i < 8; i++ {
-        b.Squares[1][i] = Pawn | White
+        b.Squares[1][i] = WhitePawn
     }
-    b.Squares[7][0] = Rook | Black
-    b.Squares[7][1] = Knight | Black
-    b.Squares[7][2] = Bishop | Black
-    b.Squares[7][3] = Queen | Black
-    b.Squares[7][4] = King | Black
-    b.Squares[7][5] = Bishop | Black
-    b.Squares[7][6] = Knight | Black
-    b.Squares[7][7] = Rook | Black
+    b.Squares[7][0] = BlackRook
+    b.Squares[7][1] = BlackKnight
+    b.Squares[7][2] = BlackBishop
+    b.Squares[7][3] = BlackQueen
+    b.Squares[7][4] = BlackKing
+    b.Squares[7][5] = BlackBishop
+    b.Squares[7][6] = BlackKnight
+    b.Squares[7][7] = BlackRook
     for i := 0; i < 8; i++ {
-        b.Squares[6][i] = Pawn | Black
+        b.Squares[6][i] = BlackPawn
     }
 }
\ No newline at end of file
@@ -1,15 +1,26 @@
 package board
 
 import (
+    "encoding/json"
+    "errors"
+    "strings"
     "testing"
 )
 
+// newEmptyBoard returns a board with no pieces and White to move.
+func newEmptyBoard() *Board {
+    return &Board{
+        CurrentTurn: White,
+        EnPassant:   NoPosition,
+    }
+}
+
 func TestNewBoard(t *testing.T) {
     b := NewBoard()
-    if b.Squares[0][0] != (Rook | White) {
+    if b.Squares[0][0] != WhiteRook {
         t.Errorf("Expected Rook at (0,0), got %d", b.Squares[0][0])
     }
-    if b.Squares[7][4] != (King | Black) {
+    if b.Squares[7][4] != BlackKing {
         t.Errorf("Expected Black King at (7,4), got %d", b.Squares[7][4])
     }
 }
@@ -21,7 +32,7 @@ func TestMovePiece(t *testing.T) {
     if !b.MovePiece(start, end) {
         t.Error("Expected move to be successful")
     }
-    if b.Squares[3][0] != (Pawn | White) {
+    if b.Squares[3][0] != WhitePawn {
         t.Errorf("Expected Pawn at (3,0), got %d", b.Squares[3][0])
     }
     if b.Squares[1][0] != 0 {
@@ -36,7 +47,7 @@ func TestUndoMove(t *testing.T) {
     move := Move{Start: start, End: end, Piece: b.GetPieceAt(start)}
     b.MovePiece(start, end)
     b.UndoMove(move)
-    if b.Squares[1][0] != (Pawn | White) {
+    if b.Squares[1][0] != WhitePawn {
         t.Errorf("Expected Pawn at (1,0), got %d", b.Squares[1][0])
     }
     if b.Squares[3][0] != 0 {
@@ -62,8 +73,7 @@ func TestKingsideCastling(t *testing.T) {
     // Clear the path for White castling
     b.Squares[0][5] = 0
     b.Squares[0][6] = 0
-    b.WhiteKingMoved = false
-    b.WhiteRookMoved[1] = false
+    b.Castling |= WhiteKingside
 
     if !b.canCastleKingside(false) {
         t.Error("Expected White to be able to castle kingside")
@@ -77,8 +87,7 @@ func TestQueensideCastling(t *testing.T) {
     b.Squares[7][1] = 0
     b.Squares[7][2] = 0
     b.Squares[7][3] = 0
-    b.BlackKingMoved = false
-    b.BlackRookMoved[0] = false
+    b.Castling |= BlackQueenside
 
     if !b.canCastleQueenside(true) {
         t.Error("Expected Black to be able to castle queenside")
@@ -101,7 +110,8 @@ func TestPawnMovement(t *testing.T) {
     }
 
     // Test pawn diagonal capture
-    b.Squares[2][1] = (Pawn | Black)
+    b = NewBoard()
+    b.Squares[2][1] = BlackPawn
     if !b.MovePiece(Position{1, 0}, Position{2, 1}) {
         t.Error("Expected pawn to capture diagonally")
     }
@@ -110,33 +120,37 @@ func TestPawnMovement(t *testing.T) {
 func TestPawnEnPassant(t *testing.T) {
     b := NewBoard()
 
-    // Black pawn moves two steps forward
+    // White pawn advances to the fifth rank
+    b.MovePiece(Position{1, 0}, Position{3, 0})
+    b.MovePiece(Position{6, 7}, Position{5, 7})
+    b.MovePiece(Position{3, 0}, Position{4, 0})
+
+    // Black pawn moves two steps forward next to it
     if !b.MovePiece(Position{6, 1}, Position{4, 1}) {
         t.Error("Expected black pawn to move forward by 2 steps")
     }
 
-    // White pawn moves two steps forward
-    if !b.MovePiece(Position{1, 0}, Position{3, 0}) {
-        t.Error("Expected white pawn to move forward by 2 steps")
-    }
-
     // Perform en passant move
-    if !b.MovePiece(Position{3, 0}, Position{4, 1}) {
+    if !b.MovePiece(Position{4, 0}, Position{5, 1}) {
         t.Error("Expected en passant capture to be successful")
     }
+    if !b.IsEmpty(Position{4, 1}) {
+        t.Error("Expected the captured pawn to be removed")
+    }
 }
 
 func TestRookMovement(t *testing.T) {
     b := NewBoard()
 
     // Test rook horizontal move
-    b.Squares[0][0] = (Rook | White)
-    if !b.MovePiece(Position{0, 0}, Position{0, 5}) {
+    b.Squares[0][1], b.Squares[0][2], b.Squares[0][3] = 0, 0, 0
+    if !b.MovePiece(Position{0, 0}, Position{0, 3}) {
         t.Error("Expected rook to move horizontally")
     }
 
     // Test rook vertical move
     b = NewBoard()
+    b.Squares[1][0] = 0
     if !b.MovePiece(Position{0, 0}, Position{5, 0}) {
         t.Error("Expected rook to move vertically")
     }
@@ -144,7 +158,7 @@ func TestRookMovement(t *testing.T) {
 
 func TestRookBlockedMovement(t *testing.T) {
     b := NewBoard()
-    b.Squares[0][1] = (Pawn | White) // Block rook
+    b.Squares[0][1] = WhitePawn // Block rook
     if b.MovePiece(Position{0, 0}, Position{0, 2}) {
         t.Error("Expected rook move to fail due to blocking piece")
     }
@@ -163,7 +177,7 @@ func TestKnightMovement(t *testing.T) {
 func TestKingCastlingQueensideBlocked(t *testing.T) {
     b := NewBoard()
     b.Squares[0][1], b.Squares[0][2] = 0, 0 // Clear queenside
-    b.Squares[0][3] = (Pawn | White)        // Block path
+    b.Squares[0][3] = WhitePawn // Block path
 
     if b.MovePiece(Position{0, 4}, Position{0, 2}) {
         t.Error("Expected castling queenside to fail due to blocking piece")
@@ -174,31 +188,41 @@ func TestBishopMovement(t *testing.T) {
     b := NewBoard()
 
     // Test bishop diagonal move
-    b.Squares[0][2] = (Bishop | White)
+    b.Squares[1][3] = 0
     if !b.MovePiece(Position{0, 2}, Position{3, 5}) {
         t.Error("Expected bishop to move diagonally")
     }
 }
 
 func TestQueenMovement(t *testing.T) {
-    b := NewBoard()
+    b := newEmptyBoard()
+    b.Squares[0][4] = WhiteKing
+    b.Squares[7][4] = BlackKing
 
     // Test queen horizontal move
-    b.Squares[0][3] = (Queen | White)
-    if !b.MovePiece(Position{0, 3}, Position{0, 5}) {
+    b.Squares[0][3] = WhiteQueen
+    if !b.MovePiece(Position{0, 3}, Position{0, 0}) {
         t.Error("Expected queen to move horizontally")
     }
+    b.MovePiece(Position{7, 4}, Position{7, 5})
 
     // Test queen diagonal move
-    if !b.MovePiece(Position{0, 5}, Position{3, 8}) {
+    if !b.MovePiece(Position{0, 0}, Position{3, 3}) {
         t.Error("Expected queen to move diagonally")
     }
+    b.MovePiece(Position{7, 5}, Position{7, 4})
+
+    // Test queen move off the board
+    if b.MovePiece(Position{3, 3}, Position{6, 8}) {
+        t.Error("Expected queen move off the board to fail")
+    }
 }
 
 func TestKingMovement(t *testing.T) {
     b := NewBoard()
 
     // Test king one step move
+    b.Squares[1][4] = 0
     if !b.MovePiece(Position{0, 4}, Position{1, 4}) {
         t.Error("Expected king to move one square")
     }
@@ -221,29 +245,230 @@ func TestKingMovement(t *testing.T) {
 // --- Check and Checkmate ---
 func TestCheck(t *testing.T) {
     b := NewBoard()
-    b.Squares[1][4] = (Rook | Black) // Place Black rook to check White king
+    b.Squares[1][4] = BlackRook // Place Black rook to check White king
     if !b.IsCheck(false) { // White is in check
         t.Error("Expected White to be in check")
     }
 }
 
-func TestCheckmate(t *testing.T) {
+func TestCheckBlockedSlider(t *testing.T) {
     b := NewBoard()
-    b.Squares[0][4] = (King | White) // White king
-    b.Squares[7][4] = (King | Black) // Black king
-    b.Squares[7][7] = (Rook | Black) // Black rook to deliver checkmate
+    b.Squares[4][4] = BlackRook // Pawn on e2 shields the king
+    if b.IsCheck(false) {
+        t.Error("Expected the e2 pawn to block the rook")
+    }
+    b.Squares[1][4] = 0
+    if !b.IsCheck(false) {
+        t.Error("Expected the rook to give check once the file opens")
+    }
+}
+
+func TestCheckByEveryPieceType(t *testing.T) {
+    tests := []struct {
+        name  string
+        piece Piece
+        pos   Position
+    }{
+        {"knight", WhiteKnight, Position{5, 3}},
+        {"bishop", WhiteBishop, Position{4, 1}},
+        {"queen diagonal", WhiteQueen, Position{3, 0}},
+        {"queen file", WhiteQueen, Position{2, 4}},
+        {"rook", WhiteRook, Position{7, 0}},
+        {"pawn", WhitePawn, Position{6, 5}},
+        {"king", WhiteKing, Position{6, 4}},
+    }
+
+    for _, tt := range tests {
+        b := newEmptyBoard()
+        b.Squares[7][4] = BlackKing
+        b.Squares[tt.pos.Row][tt.pos.Col] = tt.piece
+        if !b.IsCheck(true) {
+            t.Errorf("Expected Black to be in check from a %s", tt.name)
+        }
+    }
+
+    // A black pawn attacks downwards, so it cannot check a king behind it
+    b := newEmptyBoard()
+    b.Squares[3][4] = WhiteKing
+    b.Squares[2][3] = BlackPawn
+    if b.IsCheck(false) {
+        t.Error("Expected pawn behind the king not to give check")
+    }
+}
+
+func TestAttackersOf(t *testing.T) {
+    b := newEmptyBoard()
+    target := Position{3, 3}
+    b.Squares[2][2] = WhitePawn   // Attacks d4
+    b.Squares[1][2] = WhiteKnight // Attacks d4
+    b.Squares[3][7] = BlackRook   // Attacks d4 along the rank
+    b.Squares[6][6] = BlackBishop // Attacks d4 along the diagonal
+    b.Squares[7][3] = BlackQueen  // Blocked by the pawn on d6
+    b.Squares[5][3] = BlackPawn   // Pushes, does not attack d4
+
+    if got := len(b.AttackersOf(target)); got != 4 {
+        t.Errorf("Expected 4 attackers of d4, got %d: %v", got, b.AttackersOf(target))
+    }
+    if !b.IsSquareAttacked(target, White) || !b.IsSquareAttacked(target, Black) {
+        t.Error("Expected d4 to be attacked by both colors")
+    }
+    if b.IsSquareAttacked(Position{7, 0}, White) {
+        t.Error("Expected a8 not to be attacked by White")
+    }
+}
+
+func TestIsCheckAfterMove(t *testing.T) {
+    b := newEmptyBoard()
+    b.Squares[0][4] = WhiteKing
+    b.Squares[0][0] = BlackRook
+    b.Squares[7][7] = BlackKing
+    // The king cannot step along the rook's rank, even away from it
+    if !b.IsCheckAfterMove(Position{0, 5}, false) {
+        t.Error("Expected f1 to be attacked through the king's old square")
+    }
+    if b.IsCheckAfterMove(Position{1, 4}, false) {
+        t.Error("Expected e2 to be safe")
+    }
+}
+
+func TestCheckmate(t *testing.T) {
+    b := newEmptyBoard()
+    b.Squares[0][7] = WhiteKing // White king boxed in by its own pawns
+    b.Squares[1][6] = WhitePawn
+    b.Squares[1][7] = WhitePawn
+    b.Squares[7][4] = BlackKing // Black king
+    b.Squares[0][0] = BlackRook // Black rook to deliver checkmate
     if !b.IsCheckmate(false) {
         t.Error("Expected White to be in checkmate")
     }
+    if b.IsStalemate(false) {
+        t.Error("Expected checkmate not to be reported as stalemate")
+    }
+}
+
+func TestNotCheckmateWhenKingCanEscape(t *testing.T) {
+    b := newEmptyBoard()
+    b.Squares[0][7] = WhiteKing
+    b.Squares[1][6] = WhitePawn // h2 is free for the king
+    b.Squares[7][4] = BlackKing
+    b.Squares[0][0] = BlackRook
+    if b.IsCheckmate(false) {
+        t.Error("Expected White to escape the check")
+    }
 }
 
 func TestStalemate(t *testing.T) {
-    b := NewBoard()
-    b.Squares[0][4] = (King | White) // White king in stalemate position
-    b.Squares[1][5] = (Rook | Black) // Black rook blocks
+    b := newEmptyBoard()
+    b.Squares[0][0] = WhiteKing // White king in stalemate position
+    b.Squares[7][1] = BlackRook // Black rook covers the b-file
+    b.Squares[1][7] = BlackRook // Black rook covers the second rank
+    b.Squares[7][7] = BlackKing
     if !b.IsStalemate(false) {
         t.Error("Expected White to be in stalemate")
     }
+    if b.IsCheckmate(false) {
+        t.Error("Expected stalemate not to be reported as checkmate")
+    }
+}
+
+// --- Move generation ---
+func TestLegalMovesStartPosition(t *testing.T) {
+    b := NewBoard()
+    if got := len(b.LegalMoves()); got != 20 {
+        t.Errorf("Expected 20 legal moves in the start position, got %d", got)
+    }
+    if got := len(b.GenerateMoves(Position{0, 1})); got != 2 {
+        t.Errorf("Expected knight on b1 to have 2 moves, got %d", got)
+    }
+    if got := b.GenerateMoves(Position{0, 0}); len(got) != 0 {
+        t.Errorf("Expected rook on a1 to have no moves, got %v", got)
+    }
+}
+
+func TestLegalMovesPromotion(t *testing.T) {
+    b := newEmptyBoard()
+    b.Squares[0][4] = WhiteKing
+    b.Squares[7][7] = BlackKing
+    b.Squares[6][0] = WhitePawn
+    b.Squares[7][1] = BlackKnight
+
+    promotions := map[PieceType]int{}
+    for _, move := range b.LegalMoves() {
+        if move.Start == (Position{6, 0}) {
+            promotions[move.Promotion]++
+        }
+    }
+    for _, piece := range []PieceType{Queen, Rook, Bishop, Knight} {
+        if promotions[piece] != 2 {
+            t.Errorf("Expected a push and a capture promoting to %s, got %d", piece, promotions[piece])
+        }
+    }
+    if got := len(b.GenerateMoves(Position{6, 0})); got != 2 {
+        t.Errorf("Expected 2 promotion targets, got %d", got)
+    }
+    if b.IsValidMove(Move{Start: Position{6, 0}, End: Position{7, 0}, Piece: WhitePawn}) {
+        t.Error("Expected promotion without a piece to be invalid")
+    }
+    if !b.IsValidMove(Move{Start: Position{6, 0}, End: Position{7, 0}, Piece: WhitePawn, Promotion: Knight}) {
+        t.Error("Expected promotion to a knight to be valid")
+    }
+}
+
+func TestLegalMovesEnPassant(t *testing.T) {
+    b := newEmptyBoard()
+    b.Squares[0][4] = WhiteKing
+    b.Squares[7][4] = BlackKing
+    b.Squares[4][4] = WhitePawn
+    b.Squares[4][3] = BlackPawn
+    b.EnPassant = Position{5, 3}
+
+    found := false
+    for _, end := range b.GenerateMoves(Position{4, 4}) {
+        if end == (Position{5, 3}) {
+            found = true
+        }
+    }
+    if !found {
+        t.Error("Expected en passant capture to be generated")
+    }
+}
+
+func TestLegalMovesCastling(t *testing.T) {
+    b := newEmptyBoard()
+    b.Squares[0][4] = WhiteKing
+    b.Squares[0][0] = WhiteRook
+    b.Squares[0][7] = WhiteRook
+    b.Squares[7][4] = BlackKing
+    b.Castling = AllCastling
+
+    castles := 0
+    for _, end := range b.GenerateMoves(Position{0, 4}) {
+        if end.Col == 6 || end.Col == 2 {
+            castles++
+        }
+    }
+    if castles != 2 {
+        t.Errorf("Expected both castling moves, got %d", castles)
+    }
+
+    // A rook attacking f1 forbids castling through it
+    b.Squares[5][5] = BlackRook
+    for _, end := range b.GenerateMoves(Position{0, 4}) {
+        if end.Col == 6 {
+            t.Error("Expected kingside castling through check to be illegal")
+        }
+    }
+}
+
+func TestLegalMovesPinnedPiece(t *testing.T) {
+    b := newEmptyBoard()
+    b.Squares[0][4] = WhiteKing
+    b.Squares[1][4] = WhiteBishop // Pinned against the king
+    b.Squares[7][4] = BlackRook
+    b.Squares[7][0] = BlackKing
+    if got := b.GenerateMoves(Position{1, 4}); len(got) != 0 {
+        t.Errorf("Expected pinned bishop to have no moves, got %v", got)
+    }
 }
 
 // --- Captures ---
@@ -251,35 +476,77 @@ func TestCaptures(t *testing.T) {
     b := NewBoard()
 
     // Test capturing opponent piece
-    b.Squares[1][0] = (Rook | White)
-    b.Squares[2][0] = (Pawn | Black)
+    b.Squares[1][0] = WhiteRook
+    b.Squares[2][0] = BlackPawn
     if !b.MovePiece(Position{1, 0}, Position{2, 0}) {
         t.Error("Expected White rook to capture Black pawn")
     }
 
+    b.MovePiece(Position{6, 7}, Position{5, 7})
+
     // Test attempting to capture same color piece
-    b.Squares[3][0] = (Pawn | White)
-    if b.MovePiece(Position{1, 0}, Position{3, 0}) {
-        t.Error("Expected move to fail, cannot capture same color piece")
+    b.Squares[3][0] = WhitePawn
+    if err := b.TryMove(Move{Start: Position{2, 0}, End: Position{3, 0}}); !errors.Is(err, ErrIllegalPattern) {
+        t.Errorf("Expected move to fail, cannot capture same color piece, got %v", err)
     }
 }
 
 // --- Special Rules ---
 func TestPawnPromotion(t *testing.T) {
     b := NewBoard()
-    b.Squares[6][0] = (Pawn | White)
+    b.Squares[6][0] = WhitePawn
+    b.Squares[7][0] = 0
     b.MovePiece(Position{6, 0}, Position{7, 0})
-    if b.GetPieceAt(Position{7, 0}) != (Queen | White) {
+    if b.GetPieceAt(Position{7, 0}) != WhiteQueen {
         t.Error("Expected pawn to promote to Queen")
     }
 }
 
+func TestUnderpromotion(t *testing.T) {
+    b := NewBoard()
+    b.Squares[6][0] = WhitePawn
+    if !b.MovePiece(Position{6, 0}, Position{7, 1}, Knight) {
+        t.Fatal("Expected pawn to capture and promote")
+    }
+    if b.GetPieceAt(Position{7, 1}) != WhiteKnight {
+        t.Errorf("Expected pawn to promote to Knight, got %d", b.GetPieceAt(Position{7, 1}))
+    }
+
+    b.UnmakeMove()
+    if b.GetPieceAt(Position{6, 0}) != WhitePawn || b.GetPieceAt(Position{7, 1}) != BlackKnight {
+        t.Error("Expected unmaking the promotion to restore the pawn and captured knight")
+    }
+}
+
+func TestInvalidPromotion(t *testing.T) {
+    b, err := FromFEN("4k3/P7/8/8/8/8/4P3/4K3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    moves := []Move{
+        {Start: Position{6, 0}, End: Position{7, 0}, Promotion: King},
+        {Start: Position{6, 0}, End: Position{7, 0}, Promotion: Pawn},
+        {Start: Position{6, 0}, End: Position{7, 0}, Promotion: PieceType(WhiteQueen)},
+        {Start: Position{1, 4}, End: Position{2, 4}, Promotion: Queen},
+    }
+    for _, move := range moves {
+        if err := b.TryMove(move); !errors.Is(err, ErrInvalidPromotion) {
+            t.Errorf("Expected %v to be an invalid promotion, got %v", move, err)
+        }
+    }
+    if err := b.TryMove(Move{Start: Position{6, 0}, End: Position{7, 0}, Promotion: Rook}); err != nil {
+        t.Errorf("Expected promotion to a rook to be legal, got %v", err)
+    }
+}
+
 func Test50MoveRule(t *testing.T) {
     b := NewBoard()
 
-    for i := 0; i < 50; i++ {
-        b.MovePiece(Position{0, 1}, Position{2, 1}) // Move and reset piece
-        b.MovePiece(Position{2, 1}, Position{0, 1})
+    for i := 0; i < 25; i++ {
+        b.MovePiece(Position{0, 1}, Position{2, 2}) // Move and reset knights
+        b.MovePiece(Position{7, 1}, Position{5, 2})
+        b.MovePiece(Position{2, 2}, Position{0, 1})
+        b.MovePiece(Position{5, 2}, Position{7, 1})
     }
 
     if !b.IsDrawByFiftyMoveRule() {
@@ -291,14 +558,12 @@ func TestThreefoldRepetition(t *testing.T) {
     b := NewBoard()
 
     // Simulate threefold repetition
-    b.MovePiece(Position{1, 0}, Position{2, 0})
-    b.MovePiece(Position{2, 0}, Position{1, 0}) // Back to start
-
-    b.MovePiece(Position{1, 0}, Position{2, 0})
-    b.MovePiece(Position{2, 0}, Position{1, 0}) // Repeat
-
-    b.MovePiece(Position{1, 0}, Position{2, 0})
-    b.MovePiece(Position{2, 0}, Position{1, 0}) // Third repetition
+    for i := 0; i < 3; i++ {
+        b.MovePiece(Position{0, 6}, Position{2, 5})
+        b.MovePiece(Position{7, 6}, Position{5, 5})
+        b.MovePiece(Position{2, 5}, Position{0, 6})
+        b.MovePiece(Position{5, 5}, Position{7, 6}) // Back to start
+    }
 
     if !b.IsDrawByThreefoldRepetition() {
         t.Error("Expected draw by threefold repetition")
@@ -319,4 +584,1470 @@ func TestTurnManagement(t *testing.T) {
     if b.GetCurrentTurn() != Black {
         t.Error("Expected Black's turn after White's move")
     }
-}
\ No newline at end of file
+}
+// --- FEN ---
+func TestFENStartPosition(t *testing.T) {
+    b := NewBoard()
+    if got := b.FEN(); got != StartFEN {
+        t.Errorf("Expected start FEN %q, got %q", StartFEN, got)
+    }
+
+    parsed, err := FromFEN(StartFEN)
+    if err != nil {
+        t.Fatalf("Expected start FEN to parse, got %v", err)
+    }
+    if parsed.Squares != b.Squares {
+        t.Error("Expected parsed start FEN to match NewBoard")
+    }
+    if parsed.CurrentTurn != White {
+        t.Error("Expected White to move")
+    }
+}
+
+func TestFENRoundTrip(t *testing.T) {
+    fens := []string{
+        "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+        "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+        "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+        "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b Kq e3 0 3",
+        "4k3/8/8/3pP3/8/8/8/4K3 w - d6 12 40",
+    }
+    for _, fen := range fens {
+        b, err := FromFEN(fen)
+        if err != nil {
+            t.Errorf("Expected %q to parse, got %v", fen, err)
+            continue
+        }
+        if got := b.FEN(); got != fen {
+            t.Errorf("Expected round trip of %q, got %q", fen, got)
+        }
+    }
+}
+
+func TestFENEnPassantEnablesCapture(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !b.IsValidMove(Move{Start: Position{4, 4}, End: Position{5, 3}, Piece: WhitePawn}) {
+        t.Error("Expected exd6 en passant to be legal")
+    }
+}
+
+func TestFENErrors(t *testing.T) {
+    bad := []string{
+        "",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNRR w KQkq - 0 1",
+        "rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN w KQkq - 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e4 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e6 0 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - x 1",
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0",
+        "rnbqqbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+        "rnbqkbnp/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+        // Castling rights without a rook on that side of the king
+        "rnbqkbn1/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+        "4k3/8/8/8/8/8/8/4K2R w Q - 0 1",
+        "r3k3/8/8/8/8/8/8/R3K3 w Qk - 0 1",
+        "4k3/8/8/8/8/8/8/R3K3 w C - 0 1",
+    }
+    for _, fen := range bad {
+        if _, err := FromFEN(fen); err == nil {
+            t.Errorf("Expected %q to be rejected", fen)
+        }
+    }
+}
+
+func TestFENFieldCountError(t *testing.T) {
+    _, err := FromFEN("8/8/8/8/8/8/8/8 w")
+    if err == nil || err.Error() != "fen: expected 4 or 6 fields, got 2" {
+        t.Errorf("Expected a field count error, got %v", err)
+    }
+}
+
+func TestHalfMoveClockMirrorsFiftyMoveCount(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/8/8/8/4P3/4K1N1 w - - 12 40")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if b.HalfMoveClock != 12 {
+        t.Errorf("Expected the halfmove clock from the FEN, got %d", b.HalfMoveClock)
+    }
+    b.MovePiece(Position{0, 6}, Position{2, 5})
+    if b.HalfMoveClock != 13 || b.HalfMoveClock != b.FiftyMoveCount {
+        t.Errorf("Expected 13 after a knight move, got %d", b.HalfMoveClock)
+    }
+    b.UnmakeMove()
+    if b.HalfMoveClock != 12 {
+        t.Errorf("Expected UnmakeMove to restore 12, got %d", b.HalfMoveClock)
+    }
+}
+
+// --- Make and unmake ---
+func TestMakeUnmakeRestoresPosition(t *testing.T) {
+    fens := []string{
+        StartFEN,
+        "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+        "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3",
+        "n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+    }
+    for _, fen := range fens {
+        b, err := FromFEN(fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        before := *b
+        for _, move := range b.LegalMoves() {
+            b.MakeMove(move)
+            for _, reply := range b.LegalMoves() {
+                b.MakeMove(reply)
+                b.UnmakeMove()
+            }
+            b.UnmakeMove()
+
+            if b.FEN() != fen || b.Squares != before.Squares || b.LastMove != before.LastMove {
+                t.Fatalf("Position %q not restored after %v, got %q", fen, move, b.FEN())
+            }
+            if b.Hash() != before.Hash() || len(b.History()) != 0 {
+                t.Fatalf("Expected history to be unwound after %v", move)
+            }
+        }
+    }
+}
+
+func TestMakeMoveSpecialMoves(t *testing.T) {
+    b, err := FromFEN("r3k2r/8/8/3pP3/8/8/6p1/R3K2R w KQkq d6 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // En passant removes the captured pawn
+    b.MakeMove(Move{Start: Position{4, 4}, End: Position{5, 3}})
+    if !b.IsEmpty(Position{4, 3}) {
+        t.Error("Expected en passant to remove the d5 pawn")
+    }
+    if b.FiftyMoveCount != 0 {
+        t.Error("Expected en passant to reset the fifty-move count")
+    }
+
+    // Capturing promotion
+    b.MakeMove(Move{Start: Position{1, 6}, End: Position{0, 7}, Promotion: Knight})
+    if b.GetPieceAt(Position{0, 7}) != BlackKnight {
+        t.Errorf("Expected black knight on h1, got %d", b.GetPieceAt(Position{0, 7}))
+    }
+
+    // Castling moves the rook
+    b.MakeMove(Move{Start: Position{0, 4}, End: Position{0, 2}})
+    if b.GetPieceAt(Position{0, 3}) != WhiteRook || !b.IsEmpty(Position{0, 0}) {
+        t.Error("Expected queenside castling to move the rook to d1")
+    }
+
+    for i := 0; i < 3; i++ {
+        if _, ok := b.UnmakeMove(); !ok {
+            t.Fatalf("Expected move %d to be taken back", i)
+        }
+    }
+    if _, ok := b.UnmakeMove(); ok {
+        t.Error("Expected no more moves to take back")
+    }
+    if want := "r3k2r/8/8/3pP3/8/8/6p1/R3K2R w KQkq d6 0 1"; b.FEN() != want {
+        t.Errorf("Expected %q after unmaking, got %q", want, b.FEN())
+    }
+}
+
+func TestTryMoveErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        fen  string
+        move Move
+        want error
+    }{
+        {"off the board", StartFEN, Move{Start: Position{1, 0}, End: Position{8, 0}}, ErrOutOfBounds},
+        {"empty square", StartFEN, Move{Start: Position{3, 3}, End: Position{4, 3}}, ErrNoPiece},
+        {"opponent piece", StartFEN, Move{Start: Position{6, 4}, End: Position{4, 4}}, ErrNotYourTurn},
+        {"bad pattern", StartFEN, Move{Start: Position{0, 1}, End: Position{2, 1}}, ErrIllegalPattern},
+        {"own piece", StartFEN, Move{Start: Position{0, 0}, End: Position{1, 0}}, ErrIllegalPattern},
+        {"pinned", "4k3/4r3/8/8/8/8/4B3/4K3 w - - 0 1", Move{Start: Position{1, 4}, End: Position{2, 3}}, ErrLeavesKingInCheck},
+        {"no promotion", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", Move{Start: Position{6, 0}, End: Position{7, 0}}, ErrPromotionRequired},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN(tt.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        before := b.FEN()
+        if err := b.TryMove(tt.move); !errors.Is(err, tt.want) {
+            t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+        }
+        if b.FEN() != before {
+            t.Errorf("%s: expected a rejected move to leave the board untouched", tt.name)
+        }
+    }
+
+    b := NewBoard()
+    if err := b.TryMove(Move{Start: Position{1, 4}, End: Position{3, 4}}); err != nil {
+        t.Errorf("Expected e2e4 to be legal, got %v", err)
+    }
+    if b.CurrentTurn != Black {
+        t.Error("Expected Black to move after e2e4")
+    }
+}
+
+// --- Castling rights ---
+func TestCastlingMovesRook(t *testing.T) {
+    b, err := FromFEN("r3k2r/pppppppp/8/8/8/8/PPPPPPPP/R3K2R w KQkq - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !b.MovePiece(Position{0, 4}, Position{0, 6}) {
+        t.Fatal("Expected White to castle kingside")
+    }
+    if b.GetPieceAt(Position{0, 5}) != WhiteRook || !b.IsEmpty(Position{0, 7}) {
+        t.Error("Expected the h1 rook to move to f1")
+    }
+    if !b.MovePiece(Position{7, 4}, Position{7, 2}) {
+        t.Fatal("Expected Black to castle queenside")
+    }
+    if b.GetPieceAt(Position{7, 3}) != BlackRook || !b.IsEmpty(Position{7, 0}) {
+        t.Error("Expected the a8 rook to move to d8")
+    }
+    if b.Castling != NoCastling {
+        t.Errorf("Expected castling to use up both sides' rights, got %s", b.Castling)
+    }
+}
+
+func TestCastlingRightsUpdates(t *testing.T) {
+    tests := []struct {
+        name  string
+        moves [][2]Position
+        want  CastlingRights
+    }{
+        {"king move", [][2]Position{{{0, 4}, {0, 5}}}, BlackKingside | BlackQueenside},
+        {"kingside rook move", [][2]Position{{{0, 7}, {0, 6}}}, WhiteQueenside | BlackKingside | BlackQueenside},
+        {"queenside rook move", [][2]Position{{{0, 0}, {0, 1}}, {{7, 0}, {7, 1}}}, WhiteKingside | BlackKingside},
+        {"rook captured at home", [][2]Position{{{3, 0}, {7, 0}}}, WhiteKingside | WhiteQueenside | BlackKingside},
+        {"rook returns home", [][2]Position{{{0, 7}, {0, 6}}, {{7, 4}, {7, 5}}, {{0, 6}, {0, 7}}}, WhiteQueenside},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN("r3k2r/8/8/8/R7/8/8/R3K2R w KQkq - 0 1")
+        if err != nil {
+            t.Fatal(err)
+        }
+        for _, m := range tt.moves {
+            if !b.MovePiece(m[0], m[1]) {
+                t.Fatalf("%s: expected %v to be legal", tt.name, m)
+            }
+        }
+        if b.Castling != tt.want {
+            t.Errorf("%s: expected rights %s, got %s", tt.name, tt.want, b.Castling)
+        }
+        for range tt.moves {
+            b.UnmakeMove()
+        }
+        if b.Castling != AllCastling {
+            t.Errorf("%s: expected unmaking to restore all rights, got %s", tt.name, b.Castling)
+        }
+    }
+}
+
+func TestCastlingAfterKingReturns(t *testing.T) {
+    b, err := FromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    b.MovePiece(Position{0, 4}, Position{0, 5})
+    b.MovePiece(Position{7, 0}, Position{7, 1})
+    b.MovePiece(Position{0, 5}, Position{0, 4})
+    b.MovePiece(Position{7, 1}, Position{7, 0})
+    if b.MovePiece(Position{0, 4}, Position{0, 6}) {
+        t.Error("Expected castling to be illegal after the king has moved")
+    }
+    if got := b.FEN(); got != "r3k2r/8/8/8/8/8/8/R3K2R w k - 4 3" {
+        t.Errorf("Expected FEN to reflect lost rights, got %q", got)
+    }
+}
+
+// --- En passant ---
+func TestEnPassantSquare(t *testing.T) {
+    b := NewBoard()
+    if b.EnPassant != NoPosition {
+        t.Errorf("Expected no en passant square at the start, got %v", b.EnPassant)
+    }
+    b.MovePiece(Position{1, 4}, Position{3, 4})
+    if b.EnPassant != (Position{2, 4}) {
+        t.Errorf("Expected en passant square e3, got %v", b.EnPassant)
+    }
+    b.MovePiece(Position{7, 6}, Position{5, 5})
+    if b.EnPassant != NoPosition {
+        t.Errorf("Expected en passant square to clear, got %v", b.EnPassant)
+    }
+    b.UnmakeMove()
+    if b.EnPassant != (Position{2, 4}) {
+        t.Errorf("Expected unmaking to restore e3, got %v", b.EnPassant)
+    }
+}
+
+func TestEnPassantExpires(t *testing.T) {
+    b, err := FromFEN("4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    b.MovePiece(Position{6, 3}, Position{4, 3}) // d7d5
+    b.MovePiece(Position{0, 4}, Position{0, 3}) // Ke1d1 instead of capturing
+    b.MovePiece(Position{7, 4}, Position{7, 3})
+    if b.MovePiece(Position{4, 4}, Position{5, 3}) {
+        t.Error("Expected en passant to be possible only immediately")
+    }
+}
+
+func TestEnPassantFromFEN(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/8/3pP3/8/8/4K3 b - e3 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !b.MovePiece(Position{3, 3}, Position{2, 4}) {
+        t.Fatal("Expected dxe3 en passant to be legal without move history")
+    }
+    if !b.IsEmpty(Position{3, 4}) {
+        t.Error("Expected the e4 pawn to be captured")
+    }
+}
+
+func TestEnPassantInHash(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/8/5p2/8/4P3/4K3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    b.MovePiece(Position{1, 4}, Position{3, 4}) // e2e4 allows fxe3
+    if b.enPassantKey() == 0 {
+        t.Error("Expected a possible en passant capture to change the position key")
+    }
+
+    b, _ = FromFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1")
+    b.MovePiece(Position{1, 4}, Position{3, 4}) // No pawn can capture on e3
+    if b.enPassantKey() != 0 {
+        t.Error("Expected an unusable en passant square not to change the position key")
+    }
+}
+
+// --- Zobrist hashing ---
+func TestHashIncrementalMatchesFull(t *testing.T) {
+    for _, pos := range perftPositions {
+        b, err := FromFEN(pos.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        var walk func(depth int)
+        walk = func(depth int) {
+            if b.Hash() != b.computeHash() {
+                t.Fatalf("%s: incremental hash differs from full hash at %q", pos.name, b.FEN())
+            }
+            if b.PawnHash() != b.computePawnHash() {
+                t.Fatalf("%s: incremental pawn hash differs from full hash at %q", pos.name, b.FEN())
+            }
+            full := Board{Squares: b.Squares}
+            full.syncBitboards()
+            if b.bitboards != full.bitboards {
+                t.Fatalf("%s: incremental bitboards differ from Squares at %q", pos.name, b.FEN())
+            }
+            if depth == 0 {
+                return
+            }
+            for _, move := range b.LegalMoves() {
+                b.MakeMove(move)
+                walk(depth - 1)
+                b.UnmakeMove()
+            }
+        }
+        walk(3)
+    }
+}
+
+func TestPawnHashIgnoresPieces(t *testing.T) {
+    b := NewBoard()
+    start := b.PawnHash()
+    b.MovePiece(Position{0, 6}, Position{2, 5})
+    if b.PawnHash() != start {
+        t.Error("Expected a knight move to leave the pawn hash alone")
+    }
+    b.MovePiece(Position{6, 4}, Position{4, 4})
+    if b.PawnHash() == start {
+        t.Error("Expected a pawn move to change the pawn hash")
+    }
+    b.UnmakeMove()
+    if b.PawnHash() != start {
+        t.Error("Expected UnmakeMove to restore the pawn hash")
+    }
+}
+
+func TestSetPieceAtUpdatesHashes(t *testing.T) {
+    b := NewBoard()
+    start := b.Hash()
+    b.SetPieceAt(Position{0, 3}, NoPiece)
+    if b.Hash() == start {
+        t.Error("Expected removing the queen to change the hash")
+    }
+    for col := 0; col < 8; col++ {
+        b.SetPieceAt(Position{1, col}, NoPiece)
+    }
+    if b.Hash() != b.computeHash() {
+        t.Error("Expected the hash to match a full recompute")
+    }
+    if b.PawnHash() != b.computePawnHash() {
+        t.Error("Expected the pawn hash to match a full recompute")
+    }
+    b.SetPieceAt(Position{0, 3}, WhiteQueen)
+    b.SetPieceAt(Position{3, 3}, WhitePawn)
+    if b.Hash() != b.computeHash() || b.PawnHash() != b.computePawnHash() {
+        t.Error("Expected placing pieces to keep the hashes in step")
+    }
+}
+
+func TestDirectSquaresWritesAreSynced(t *testing.T) {
+    b := NewBoard()
+    b.Squares[1][4] = 0
+    b.Squares[4][4] = BlackRook
+    if !b.IsCheck(false) {
+        t.Error("Expected the rook written to Squares to give check")
+    }
+    if b.Hash() != b.computeHash() || b.PawnHash() != b.computePawnHash() {
+        t.Error("Expected the hashes to follow direct writes to Squares")
+    }
+    b.Squares[4][4] = 0
+    b.SetPieceAt(Position{4, 3}, BlackRook)
+    if b.IsCheck(false) {
+        t.Error("Expected the removed rook to give no check")
+    }
+    if got := b.Bitboards().Pieces[1][Rook].Count(); got != 3 {
+        t.Errorf("Expected 3 black rooks, got %d", got)
+    }
+}
+
+func TestHashDistinguishesState(t *testing.T) {
+    hash := func(fen string) uint64 {
+        b, err := FromFEN(fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return b.Hash()
+    }
+    base := hash("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+    if hash("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 12 30") != base {
+        t.Error("Expected the clocks not to affect the hash")
+    }
+    if hash("r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1") == base {
+        t.Error("Expected the side to move to change the hash")
+    }
+    if hash("r3k2r/8/8/8/8/8/8/R3K2R w Kkq - 0 1") == base {
+        t.Error("Expected castling rights to change the hash")
+    }
+
+    // Transpositions reach the same hash
+    a := NewBoard()
+    a.MovePiece(Position{0, 6}, Position{2, 5})
+    a.MovePiece(Position{7, 6}, Position{5, 5})
+    a.MovePiece(Position{0, 1}, Position{2, 2})
+    b := NewBoard()
+    b.MovePiece(Position{0, 1}, Position{2, 2})
+    b.MovePiece(Position{7, 6}, Position{5, 5})
+    b.MovePiece(Position{0, 6}, Position{2, 5})
+    if a.Hash() != b.Hash() {
+        t.Error("Expected transposed move orders to hash the same")
+    }
+}
+
+func TestThreefoldRepetitionUnwinds(t *testing.T) {
+    b := NewBoard()
+    for i := 0; i < 2; i++ {
+        b.MovePiece(Position{0, 6}, Position{2, 5})
+        b.MovePiece(Position{7, 6}, Position{5, 5})
+        b.MovePiece(Position{2, 5}, Position{0, 6})
+        b.MovePiece(Position{5, 5}, Position{7, 6})
+    }
+    if !b.IsDrawByThreefoldRepetition() {
+        t.Fatal("Expected the start position to have occurred three times")
+    }
+    b.UnmakeMove()
+    if b.IsDrawByThreefoldRepetition() {
+        t.Error("Expected undoing a move to undo the repetition")
+    }
+    b.MovePiece(Position{5, 5}, Position{7, 6})
+    if !b.IsDrawByThreefoldRepetition() {
+        t.Error("Expected replaying the move to repeat the position again")
+    }
+}
+
+// --- SAN ---
+func TestSANFormatting(t *testing.T) {
+    tests := []struct {
+        fen  string
+        move Move
+        want string
+    }{
+        {StartFEN, Move{Start: Position{0, 6}, End: Position{2, 5}}, "Nf3"},
+        {StartFEN, Move{Start: Position{1, 4}, End: Position{3, 4}}, "e4"},
+        {"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2", Move{Start: Position{3, 4}, End: Position{4, 3}}, "exd5"},
+        {"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", Move{Start: Position{0, 4}, End: Position{0, 6}}, "O-O"},
+        {"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", Move{Start: Position{7, 4}, End: Position{7, 2}}, "O-O-O"},
+        {"8/4P3/8/8/8/8/8/k6K w - - 0 1", Move{Start: Position{6, 4}, End: Position{7, 4}, Promotion: Queen}, "e8=Q"},
+        {"3k4/4P3/8/8/8/8/8/K7 w - - 0 1", Move{Start: Position{6, 4}, End: Position{7, 4}, Promotion: Queen}, "e8=Q+"},
+        {"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", Move{Start: Position{4, 4}, End: Position{5, 3}}, "exd6"},
+        {"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", Move{Start: Position{0, 0}, End: Position{7, 0}}, "Ra8#"},
+        // Disambiguation by file, rank and full square
+        {"4k3/8/8/8/8/8/8/1N2KN2 w - - 0 1", Move{Start: Position{0, 1}, End: Position{1, 3}}, "Nbd2"},
+        {"4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", Move{Start: Position{0, 0}, End: Position{2, 0}}, "R1a3"},
+        {"8/7k/8/8/Q2Q4/8/8/Q3K3 w - - 0 1", Move{Start: Position{3, 3}, End: Position{0, 3}}, "Qdd1"},
+        {"8/7k/8/8/Q2Q4/8/8/Q3K3 w - - 0 1", Move{Start: Position{0, 0}, End: Position{0, 3}}, "Q1d1"},
+        {"8/7k/8/8/Q2Q4/8/8/Q3K3 w - - 0 1", Move{Start: Position{3, 0}, End: Position{0, 3}}, "Qa4d1"},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN(tt.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := b.SAN(tt.move); got != tt.want {
+            t.Errorf("%s: expected %s, got %s", tt.fen, tt.want, got)
+        }
+    }
+}
+
+func TestParseSAN(t *testing.T) {
+    b := NewBoard()
+    for _, san := range []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6", "Bxc6", "dxc6", "O-O", "Bg4!?", "h3", "h5", "hxg4", "hxg4"} {
+        move, err := b.ParseSAN(san)
+        if err != nil {
+            t.Fatalf("ParseSAN(%q): %v", san, err)
+        }
+        b.MakeMove(move)
+    }
+    want := "r2qkbnr/1pp2pp1/p1p5/4p3/4P1p1/5N2/PPPP1PP1/RNBQ1RK1 w kq - 0 8"
+    if got := b.FEN(); got != want {
+        t.Errorf("Expected %s, got %s", want, got)
+    }
+}
+
+func TestParseSANVariants(t *testing.T) {
+    b, err := FromFEN("4k3/1P6/8/8/8/8/8/1N2KN2 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        san  string
+        want Move
+    }{
+        {"Nbd2", Move{Start: Position{0, 1}, End: Position{1, 3}}},
+        {"Nfd2", Move{Start: Position{0, 5}, End: Position{1, 3}}},
+        {"Nb1d2", Move{Start: Position{0, 1}, End: Position{1, 3}}},
+        {"b8=N", Move{Start: Position{6, 1}, End: Position{7, 1}, Promotion: Knight}},
+        {"b8Q+", Move{Start: Position{6, 1}, End: Position{7, 1}, Promotion: Queen}},
+    }
+    for _, tt := range tests {
+        move, err := b.ParseSAN(tt.san)
+        if err != nil {
+            t.Errorf("ParseSAN(%q): %v", tt.san, err)
+            continue
+        }
+        if move.Start != tt.want.Start || move.End != tt.want.End || move.Promotion != tt.want.Promotion {
+            t.Errorf("ParseSAN(%q): expected %+v, got %+v", tt.san, tt.want, move)
+        }
+    }
+}
+
+func TestParseSANErrors(t *testing.T) {
+    b, err := FromFEN("4k3/1P6/8/8/8/8/8/1N2KN2 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        san  string
+        want error
+    }{
+        {"", ErrInvalidSAN},
+        {"Nz9", ErrInvalidSAN},
+        {"b8=K", ErrInvalidSAN},
+        {"Nd2", ErrAmbiguousSAN},
+        {"Nd4", ErrIllegalSAN},
+        {"b8", ErrIllegalSAN},
+        {"O-O", ErrIllegalSAN},
+    }
+    for _, tt := range tests {
+        if _, err := b.ParseSAN(tt.san); !errors.Is(err, tt.want) {
+            t.Errorf("ParseSAN(%q): expected %v, got %v", tt.san, tt.want, err)
+        }
+    }
+}
+
+func TestParseSANPawnCaptureNeedsFile(t *testing.T) {
+    // Only the c4 pawn can reach d5, and only by capturing
+    b, err := FromFEN("4k3/8/8/3p4/2P5/8/8/4K3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := b.ParseSAN("d5"); !errors.Is(err, ErrIllegalSAN) {
+        t.Errorf("Expected d5 to be rejected as a capture without its file, got %v", err)
+    }
+    if _, err := b.ParseSAN("cxd5"); err != nil {
+        t.Errorf("ParseSAN(\"cxd5\"): %v", err)
+    }
+}
+
+func TestSANRoundTrip(t *testing.T) {
+    for _, pos := range perftPositions {
+        b, err := FromFEN(pos.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        for _, move := range b.LegalMoves() {
+            san := b.SAN(move)
+            parsed, err := b.ParseSAN(san)
+            if err != nil {
+                t.Errorf("%s: ParseSAN(%q): %v", pos.name, san, err)
+                continue
+            }
+            if parsed != move {
+                t.Errorf("%s: %q parsed as %+v, want %+v", pos.name, san, parsed, move)
+            }
+        }
+    }
+}
+
+// --- UCI notation ---
+func TestPositionString(t *testing.T) {
+    for _, name := range []string{"a1", "e4", "h8", "c7"} {
+        pos, err := ParsePosition(name)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := pos.String(); got != name {
+            t.Errorf("Expected %s, got %s", name, got)
+        }
+    }
+    if pos, _ := ParsePosition("e4"); pos != (Position{3, 4}) {
+        t.Errorf("Expected e4 to be row 3, col 4, got %+v", pos)
+    }
+    if NoPosition.String() != "-" {
+        t.Errorf("Expected NoPosition to print as -, got %s", NoPosition.String())
+    }
+    for _, name := range []string{"", "e", "e9", "i1", "E4", "e44"} {
+        if _, err := ParsePosition(name); err == nil {
+            t.Errorf("Expected %q to be rejected", name)
+        }
+    }
+}
+
+func TestMoveUCI(t *testing.T) {
+    tests := []struct {
+        move Move
+        want string
+    }{
+        {Move{Start: Position{1, 4}, End: Position{3, 4}}, "e2e4"},
+        {Move{Start: Position{6, 4}, End: Position{7, 4}, Promotion: Queen}, "e7e8q"},
+        {Move{Start: Position{1, 0}, End: Position{0, 1}, Promotion: Knight}, "a2b1n"},
+    }
+    for _, tt := range tests {
+        if got := tt.move.UCI(); got != tt.want {
+            t.Errorf("Expected %s, got %s", tt.want, got)
+        }
+    }
+}
+
+func TestParseUCIMove(t *testing.T) {
+    b, err := FromFEN("r3k3/1P6/8/8/8/8/8/4K2R w K - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    move, err := ParseUCIMove(b, "e1g1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if move.Piece != WhiteKing || move.End != (Position{0, 6}) {
+        t.Errorf("Expected castling with the white king, got %+v", move)
+    }
+    if move, err = ParseUCIMove(b, "b7a8r"); err != nil || move.Promotion != Rook {
+        t.Errorf("Expected a capture promoting to a rook, got %+v, %v", move, err)
+    }
+
+    tests := []struct {
+        s    string
+        want error
+    }{
+        {"e1", ErrInvalidUCI},
+        {"e1z1", ErrInvalidUCI},
+        {"b7b8k", ErrInvalidUCI},
+        {"b7b8Q", ErrInvalidUCI},
+        {"d4d5", ErrNoPiece},
+        {"a8a1", ErrNotYourTurn},
+        {"e1e3", ErrIllegalPattern},
+        {"b7b8", ErrPromotionRequired},
+        {"h1h2q", ErrInvalidPromotion},
+    }
+    for _, tt := range tests {
+        if _, err := ParseUCIMove(b, tt.s); !errors.Is(err, tt.want) {
+            t.Errorf("ParseUCIMove(%q): expected %v, got %v", tt.s, tt.want, err)
+        }
+    }
+    if b.FEN() != "r3k3/1P6/8/8/8/8/8/4K2R w K - 0 1" {
+        t.Error("Expected ParseUCIMove to leave the board untouched")
+    }
+}
+
+func TestParsePromotion(t *testing.T) {
+    for s, want := range map[string]PieceType{"q": Queen, "r": Rook, "b": Bishop, "n": Knight} {
+        if got, err := ParsePromotion(s); err != nil || got != want {
+            t.Errorf("ParsePromotion(%q): expected %v, got %v, %v", s, want, got, err)
+        }
+    }
+    for _, s := range []string{"", "k", "p", "Q", "qq"} {
+        if _, err := ParsePromotion(s); !errors.Is(err, ErrInvalidPromotion) {
+            t.Errorf("ParsePromotion(%q): expected %v, got %v", s, ErrInvalidPromotion, err)
+        }
+    }
+}
+
+// --- Piece types ---
+func TestPieceAccessors(t *testing.T) {
+    for _, pieceType := range []PieceType{Rook, Knight, Bishop, Queen, King, Pawn} {
+        for _, color := range []Color{White, Black} {
+            piece := NewPiece(pieceType, color)
+            if piece.Type() != pieceType || piece.Color() != color {
+                t.Errorf("Expected %s %s, got %s %s", color, pieceType, piece.Color(), piece.Type())
+            }
+        }
+    }
+    // Pawn shares bits with the other piece types, but no other piece is a pawn
+    for _, piece := range []Piece{WhiteKnight, WhiteBishop, WhiteQueen, WhiteKing, BlackRook} {
+        if piece.Type() == Pawn {
+            t.Errorf("Expected %s not to be a pawn", piece)
+        }
+    }
+    if NoPiece.Type() != NoPieceType {
+        t.Errorf("Expected NoPiece to have no type, got %s", NoPiece.Type())
+    }
+    if White.Opponent() != Black || Black.Opponent() != White {
+        t.Error("Expected White and Black to be opponents")
+    }
+}
+
+func TestPieceStrings(t *testing.T) {
+    tests := []struct {
+        got, want string
+    }{
+        {WhiteKnight.String(), "N"},
+        {BlackPawn.String(), "p"},
+        {NoPiece.String(), "-"},
+        {Queen.String(), "queen"},
+        {Black.String(), "black"},
+        {Square(28).String(), "e4"},
+        {NoSquare.String(), "-"},
+    }
+    for _, tt := range tests {
+        if tt.got != tt.want {
+            t.Errorf("Expected %q, got %q", tt.want, tt.got)
+        }
+    }
+}
+
+func TestSquarePosition(t *testing.T) {
+    for sq := Square(0); sq < 64; sq++ {
+        if sq.Position().Square() != sq {
+            t.Errorf("Expected %s to round-trip through Position", sq)
+        }
+    }
+    if (Position{3, 4}).Square() != 28 || NoPosition.Square() != NoSquare {
+        t.Error("Expected e4 to be square 28 and NoPosition to be NoSquare")
+    }
+    if sq, err := ParseSquare("h8"); err != nil || sq != 63 {
+        t.Errorf("Expected h8 to be square 63, got %d, %v", sq, err)
+    }
+}
+
+func TestPieceJSON(t *testing.T) {
+    value := struct {
+        Pieces []Piece
+        Type   PieceType
+        Color  Color
+        Square Square
+    }{[]Piece{WhiteKing, NoPiece, BlackKnight}, Bishop, Black, 12}
+
+    data, err := json.Marshal(value)
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := `{"Pieces":["K",null,"n"],"Type":"bishop","Color":"black","Square":"e2"}`
+    if string(data) != want {
+        t.Errorf("Expected %s, got %s", want, data)
+    }
+
+    decoded := value
+    decoded.Pieces, decoded.Type, decoded.Color, decoded.Square = nil, NoPieceType, White, NoSquare
+    if err := json.Unmarshal(data, &decoded); err != nil {
+        t.Fatal(err)
+    }
+    if decoded.Pieces[0] != WhiteKing || decoded.Pieces[1] != NoPiece || decoded.Pieces[2] != BlackKnight ||
+        decoded.Type != Bishop || decoded.Color != Black || decoded.Square != 12 {
+        t.Errorf("Expected the JSON to round-trip, got %+v", decoded)
+    }
+
+    for _, bad := range []string{`"x"`, `"KQ"`, `5`} {
+        var piece Piece
+        if err := json.Unmarshal([]byte(bad), &piece); err == nil {
+            t.Errorf("Expected %s to be rejected as a piece", bad)
+        }
+    }
+}
+
+// --- Draw rules and outcome ---
+func TestFiftyMoveRuleCountsPlies(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 99 80")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if b.IsDrawByFiftyMoveRule() {
+        t.Error("Expected 99 plies not to be enough for the 50-move rule")
+    }
+    b.MovePiece(Position{0, 0}, Position{1, 0})
+    if !b.IsDrawByFiftyMoveRule() {
+        t.Error("Expected 100 plies to allow a 50-move claim")
+    }
+    if b.IsDrawBySeventyFiveMoveRule() || b.Outcome().Termination != NotTerminated {
+        t.Error("Expected the 50-move rule not to end the game by itself")
+    }
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+    tests := []struct {
+        fen  string
+        want bool
+    }{
+        {"4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+        {"4k3/8/8/8/8/8/8/4KN2 w - - 0 1", true},
+        {"4kb2/8/8/8/8/8/8/4K3 w - - 0 1", true},
+        {"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", true},  // Both bishops on dark squares
+        {"4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1", false}, // Bishops on both colors
+        {"4kb2/8/8/8/8/8/8/3BK3 w - - 0 1", false},
+        {"4kn2/8/8/8/8/8/8/4KN2 w - - 0 1", false},
+        {"4k3/8/8/8/8/8/8/4KNN1 w - - 0 1", false},
+        {"4k3/8/8/8/8/8/8/4K2R w - - 0 1", false},
+        {"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN(tt.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := b.IsInsufficientMaterial(); got != tt.want {
+            t.Errorf("%s: expected %v, got %v", tt.fen, tt.want, got)
+        }
+    }
+}
+
+func TestOutcome(t *testing.T) {
+    tests := []struct {
+        fen  string
+        want Outcome
+    }{
+        {StartFEN, Outcome{NoResult, NotTerminated}},
+        {"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", Outcome{BlackWon, Checkmate}},
+        {"R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1", Outcome{WhiteWon, Checkmate}},
+        {"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", Outcome{DrawResult, Stalemate}},
+        {"4k3/8/8/8/8/8/8/4KB2 w - - 0 1", Outcome{DrawResult, InsufficientMaterial}},
+        {"4k3/8/8/8/8/8/8/R3K3 w - - 149 120", Outcome{NoResult, NotTerminated}},
+        {"4k3/8/8/8/8/8/8/R3K3 w - - 150 120", Outcome{DrawResult, SeventyFiveMoveRule}},
+        // Checkmate on the 150th ply still wins
+        {"R5k1/5ppp/8/8/8/8/8/6K1 b - - 150 120", Outcome{WhiteWon, Checkmate}},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN(tt.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := b.Outcome(); got != tt.want {
+            t.Errorf("%s: expected %v, got %v", tt.fen, tt.want, got)
+        }
+    }
+}
+
+func TestFivefoldRepetition(t *testing.T) {
+    b := NewBoard()
+    shuffle := func() {
+        b.MovePiece(Position{0, 6}, Position{2, 5})
+        b.MovePiece(Position{7, 6}, Position{5, 5})
+        b.MovePiece(Position{2, 5}, Position{0, 6})
+        b.MovePiece(Position{5, 5}, Position{7, 6})
+    }
+    for i := 0; i < 2; i++ {
+        shuffle()
+    }
+    if !b.IsDrawByThreefoldRepetition() || b.Outcome().Termination != NotTerminated {
+        t.Error("Expected threefold repetition to be claimable but not to end the game")
+    }
+    shuffle()
+    if b.IsDrawByFivefoldRepetition() {
+        t.Error("Expected four occurrences not to be a fivefold repetition")
+    }
+    shuffle()
+    if got := b.Outcome(); got != (Outcome{DrawResult, FivefoldRepetition}) {
+        t.Errorf("Expected a draw by fivefold repetition, got %v", got)
+    }
+}
+
+// --- Game status ---
+func TestStatusFromPosition(t *testing.T) {
+    tests := []struct {
+        fen         string
+        status      GameStatus
+        termination Termination
+    }{
+        {StartFEN, Ongoing, NotTerminated},
+        {"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", BlackWins, Checkmate},
+        {"R5k1/5ppp/8/8/8/8/8/6K1 b - - 0 1", WhiteWins, Checkmate},
+        {"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", Draw, Stalemate},
+        {"4k3/8/8/8/8/8/8/4KN2 w - - 0 1", Draw, InsufficientMaterial},
+        {"4k3/8/8/8/8/8/8/R3K3 w - - 150 120", Draw, SeventyFiveMoveRule},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN(tt.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        status, termination := b.Status()
+        if status != tt.status || termination != tt.termination {
+            t.Errorf("%s: expected %s by %s, got %s by %s", tt.fen, tt.status, tt.termination, status, termination)
+        }
+        if status.Result() != b.Outcome().Result {
+            t.Errorf("%s: expected the status result to match the outcome", tt.fen)
+        }
+    }
+}
+
+func TestResignTimeoutAndAgreement(t *testing.T) {
+    b := NewBoard()
+    if err := b.Resign(White); err != nil {
+        t.Fatal(err)
+    }
+    if status, termination := b.Status(); status != BlackWins || termination != Resignation {
+        t.Errorf("Expected Black to win by resignation, got %s by %s", status, termination)
+    }
+    if err := b.AgreeDraw(); !errors.Is(err, ErrGameOver) {
+        t.Errorf("Expected a finished game to stay finished, got %v", err)
+    }
+
+    b = NewBoard()
+    b.MovePiece(Position{1, 4}, Position{3, 4})
+    if err := b.Timeout(Black); err != nil {
+        t.Fatal(err)
+    }
+    if status, termination := b.Status(); status != WhiteWins || termination != Timeout {
+        t.Errorf("Expected White to win on time, got %s by %s", status, termination)
+    }
+    b.MakeMove(Move{Start: Position{6, 4}, End: Position{4, 4}})
+    b.UnmakeMove()
+    if _, termination := b.Status(); termination != Timeout {
+        t.Errorf("Expected taking back a move made after the timeout to keep it, got %s", termination)
+    }
+    b.UnmakeMove()
+    if b.IsGameOver() || b.Ending().Termination != NotTerminated {
+        t.Error("Expected taking back the move before the timeout to reopen the game")
+    }
+
+    if err := b.AgreeDraw(); err != nil {
+        t.Fatal(err)
+    }
+    if status, termination := b.Status(); status != Draw || termination != Agreement {
+        t.Errorf("Expected a draw by agreement, got %s by %s", status, termination)
+    }
+}
+
+func TestClaimDraw(t *testing.T) {
+    b := NewBoard()
+    if err := b.ClaimDraw(); !errors.Is(err, ErrNoDrawToClaim) {
+        t.Errorf("Expected no draw to claim at the start, got %v", err)
+    }
+    for i := 0; i < 2; i++ {
+        b.MovePiece(Position{0, 6}, Position{2, 5})
+        b.MovePiece(Position{7, 6}, Position{5, 5})
+        b.MovePiece(Position{2, 5}, Position{0, 6})
+        b.MovePiece(Position{5, 5}, Position{7, 6})
+    }
+    if b.IsGameOver() {
+        t.Fatal("Expected threefold repetition not to end the game without a claim")
+    }
+    if err := b.ClaimDraw(); err != nil {
+        t.Fatal(err)
+    }
+    if status, termination := b.Status(); status != Draw || termination != ThreefoldRepetition {
+        t.Errorf("Expected a draw by threefold repetition, got %s by %s", status, termination)
+    }
+
+    b, err := FromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 100 80")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := b.ClaimDraw(); err != nil {
+        t.Fatal(err)
+    }
+    if _, termination := b.Status(); termination != FiftyMoveRule {
+        t.Errorf("Expected a draw by the 50-move rule, got %s", termination)
+    }
+}
+
+// --- Chess960 ---
+func TestNewChess960Board(t *testing.T) {
+    b, err := NewChess960Board(518)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if b.FEN() != StartFEN {
+        t.Errorf("Expected position 518 to be the standard setup, got %q", b.FEN())
+    }
+    if b.hash != NewBoard().hash {
+        t.Error("Expected position 518 to hash like the standard setup")
+    }
+
+    b, _ = NewChess960Board(0)
+    if got := b.FEN(); got != "bbqnnrkr/pppppppp/8/8/8/8/PPPPPPPP/BBQNNRKR w KQkq - 0 1" {
+        t.Errorf("Unexpected position 0: %q", got)
+    }
+
+    seen := make(map[string]bool)
+    for n := 0; n < 960; n++ {
+        b, err := NewChess960Board(n)
+        if err != nil {
+            t.Fatal(err)
+        }
+        placement := strings.Fields(b.FEN())[0]
+        if seen[placement] {
+            t.Fatalf("Position %d repeats %q", n, placement)
+        }
+        seen[placement] = true
+        rank := strings.SplitN(placement, "/", 2)[0]
+        bishops := strings.IndexByte(rank, 'b') + strings.LastIndexByte(rank, 'b')
+        king := strings.IndexByte(rank, 'k')
+        if bishops%2 == 0 || king < strings.IndexByte(rank, 'r') || king > strings.LastIndexByte(rank, 'r') {
+            t.Errorf("Position %d is not a valid Chess960 setup: %q", n, rank)
+        }
+    }
+
+    for _, n := range []int{-1, 960} {
+        if _, err := NewChess960Board(n); err == nil {
+            t.Errorf("Expected position %d to be rejected", n)
+        }
+    }
+}
+
+func TestChess960CastlingFEN(t *testing.T) {
+    tests := []struct {
+        fen  string
+        want string
+    }{
+        {"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w HAha - 0 1", StartFEN},
+        {"1r4kr/8/8/8/8/8/8/1R4KR w HBhb - 0 1", "1r4kr/8/8/8/8/8/8/1R4KR w KQkq - 0 1"},
+        {"r1r1k2r/8/8/8/8/8/8/R1R1K2R w HCh - 0 1", "r1r1k2r/8/8/8/8/8/8/R1R1K2R w KCk - 0 1"},
+        {"r1r1k2r/8/8/8/8/8/8/R1R1K2R w KCk - 0 1", "r1r1k2r/8/8/8/8/8/8/R1R1K2R w KCk - 0 1"},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN(tt.fen)
+        if err != nil {
+            t.Errorf("%q: %v", tt.fen, err)
+            continue
+        }
+        if got := b.FEN(); got != tt.want {
+            t.Errorf("%q: expected %q, got %q", tt.fen, tt.want, got)
+        }
+    }
+
+    for _, fen := range []string{
+        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w Ee - 0 1",
+        "4k3/8/8/8/8/8/4K3/R6R w KQ - 0 1",
+    } {
+        if _, err := FromFEN(fen); err == nil {
+            t.Errorf("Expected %q to be rejected", fen)
+        }
+    }
+}
+
+func TestChess960Castling(t *testing.T) {
+    b, err := FromFEN("1r4kr/8/8/8/8/8/8/1R4KR w HBhb - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    before := b.FEN()
+    kingside := Move{Start: Position{0, 6}, End: Position{0, 7}, Piece: WhiteKing}
+    queenside := Move{Start: Position{0, 6}, End: Position{0, 1}, Piece: WhiteKing}
+
+    if got := b.SAN(kingside); got != "O-O" {
+        t.Errorf("Expected O-O, got %q", got)
+    }
+    if got := b.SAN(queenside); got != "O-O-O" {
+        t.Errorf("Expected O-O-O, got %q", got)
+    }
+
+    b.MakeMove(kingside)
+    if got := b.FEN(); got != "1r4kr/8/8/8/8/8/8/1R3RK1 b kq - 1 1" {
+        t.Errorf("Unexpected position after O-O: %q", got)
+    }
+    if b.hash != b.computeHash() {
+        t.Error("Expected the hash to follow castling")
+    }
+    b.UnmakeMove()
+    if b.FEN() != before {
+        t.Errorf("Expected UnmakeMove to restore %q, got %q", before, b.FEN())
+    }
+
+    b.MakeMove(queenside)
+    if got := b.FEN(); got != "1r4kr/8/8/8/8/8/8/2KR3R b kq - 1 1" {
+        t.Errorf("Unexpected position after O-O-O: %q", got)
+    }
+    b.UnmakeMove()
+    if b.FEN() != before {
+        t.Errorf("Expected UnmakeMove to restore %q, got %q", before, b.FEN())
+    }
+}
+
+func TestChess960UCINotation(t *testing.T) {
+    b := NewBoard()
+    b.SetPieceAt(Position{0, 5}, NoPiece)
+    b.SetPieceAt(Position{0, 6}, NoPiece)
+    castle := Move{Start: Position{0, 4}, End: Position{0, 6}, Piece: WhiteKing}
+
+    if got := b.FormatUCI(castle, false); got != "e1g1" {
+        t.Errorf("Expected e1g1, got %q", got)
+    }
+    if got := b.FormatUCI(castle, true); got != "e1h1" {
+        t.Errorf("Expected e1h1, got %q", got)
+    }
+    move, err := ParseChess960UCIMove(b, "e1h1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if move != castle {
+        t.Errorf("Expected e1h1 to castle, got %s", move.UCI())
+    }
+    if _, err := ParseUCIMove(b, "e1h1"); err == nil {
+        t.Error("Expected e1h1 to be illegal without UCI_Chess960")
+    }
+}
+
+// --- Static exchange evaluation ---
+func TestSEE(t *testing.T) {
+    tests := []struct {
+        name string
+        fen  string
+        move string
+        want int
+    }{
+        {"undefended pawn", "1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "e1e5", 100},
+        {"defended pawn", "1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "d3e5", 100 - 320},
+        {"x-ray rook", "4k3/3r4/8/3p4/8/8/3R4/3RK3 w - - 0 1", "d2d5", 100},
+        {"no x-ray", "4k3/3r4/8/3p4/8/8/3R4/4K3 w - - 0 1", "d2d5", 100 - 500},
+        {"x-ray bishop behind queen", "4k3/8/5n2/8/3Q4/2B5/8/4K3 w - - 0 1", "d4f6", 320},
+        {"quiet move into attack", "4k3/8/8/3p4/8/2N5/8/4K3 w - - 0 1", "c3e4", -320},
+        {"quiet safe move", "4k3/8/8/8/8/2N5/8/4K3 w - - 0 1", "c3e4", 0},
+        {"king recaptures", "4k3/8/8/8/8/8/3q4/3QK3 b - - 0 1", "d2d1", 0},
+        {"king cannot recapture", "3rk3/8/8/8/8/8/3r4/3RK3 b - - 0 1", "d2d1", 500},
+        {"en passant", "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", 100},
+        {"promotion", "4k3/P7/8/8/8/8/8/4K3 w - - 0 1", "a7a8q", 800},
+        {"defended promotion", "rk6/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8q", 500 + 800 - 900},
+    }
+    for _, tt := range tests {
+        b, err := FromFEN(tt.fen)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        move, err := ParseUCIMove(b, tt.move)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        if got := b.SEE(move); got != tt.want {
+            t.Errorf("%s: SEE(%s) = %d, want %d", tt.name, tt.move, got, tt.want)
+        }
+        if !b.SEEGreaterOrEqual(move, tt.want) || b.SEEGreaterOrEqual(move, tt.want+1) {
+            t.Errorf("%s: SEEGreaterOrEqual disagrees with SEE = %d", tt.name, tt.want)
+        }
+    }
+}
+
+func TestAttackersTo(t *testing.T) {
+    b, err := FromFEN("4k3/3r4/8/3p4/8/8/3R4/3RK3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    p := b.Bitboards()
+    d5 := Position{4, 3}.Square()
+    if got := p.AttackersTo(d5, p.Occupied); got != BitboardOf(Position{6, 3})|BitboardOf(Position{1, 3}) {
+        t.Errorf("Expected d7 and d2 to attack d5, got %v", got.Positions())
+    }
+    occupied := p.Occupied &^ BitboardOf(Position{1, 3})
+    if got := p.AttackersTo(d5, occupied); !got.Has(Position{0, 3}) || got.Has(Position{1, 3}) {
+        t.Errorf("Expected the d1 rook to x-ray through d2, got %v", got.Positions())
+    }
+}
+
+// --- Pins and checks ---
+func TestKingPosition(t *testing.T) {
+    b := NewBoard()
+    if got := b.KingPosition(White); got != (Position{0, 4}) {
+        t.Errorf("Expected the white king on e1, got %v", got)
+    }
+    if got := b.KingPosition(Black); got != (Position{7, 4}) {
+        t.Errorf("Expected the black king on e8, got %v", got)
+    }
+    if got := (&Board{}).KingPosition(White); got != NoPosition {
+        t.Errorf("Expected NoPosition without a king, got %v", got)
+    }
+}
+
+func TestPinned(t *testing.T) {
+    // The e2 knight is pinned by the e8 rook and the c3 bishop by the a5
+    // queen
+    b, err := FromFEN("4r1k1/8/8/q7/8/2B5/4N3/3QK3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    pins := b.Pinned(White)
+    if len(pins) != 2 {
+        t.Fatalf("Expected 2 pins, got %v", pins)
+    }
+    byPiece := make(map[Position]Pin)
+    for _, pin := range pins {
+        byPiece[pin.Pinned] = pin
+    }
+
+    knight, ok := byPiece[Position{1, 4}]
+    if !ok || knight.Pinner != (Position{7, 4}) {
+        t.Errorf("Expected the e2 knight to be pinned by e8, got %v", pins)
+    }
+    if knight.Ray.Count() != 7 || !knight.Ray.Has(Position{7, 4}) || knight.Ray.Has(Position{0, 4}) {
+        t.Errorf("Expected the pin ray to run from e2 to e8, got %v", knight.Ray.Positions())
+    }
+    if bishop, ok := byPiece[Position{2, 2}]; !ok || bishop.Pinner != (Position{4, 0}) {
+        t.Errorf("Expected the c3 bishop to be pinned by a5, got %v", pins)
+    }
+
+    if got := b.GenerateMoves(Position{1, 4}); len(got) != 0 {
+        t.Errorf("Expected the pinned knight to have no moves, got %v", got)
+    }
+    if got := b.GenerateMoves(Position{2, 2}); len(got) != 3 {
+        t.Errorf("Expected the pinned bishop to move along the pin only, got %v", got)
+    }
+    if len(b.Pinned(Black)) != 0 {
+        t.Error("Expected no black pins")
+    }
+}
+
+func TestCheckers(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/8/1b6/8/3N4/R3K2r w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    checkers := b.Checkers()
+    if len(checkers) != 1 || checkers[0] != (Position{0, 7}) {
+        t.Fatalf("Expected the h1 rook to give check, got %v", checkers)
+    }
+
+    // Double check leaves only king moves
+    b, err = FromFEN("4k3/8/8/8/1b6/8/8/R3K2r w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := len(b.Checkers()); got != 2 {
+        t.Errorf("Expected a double check, got %d checkers", got)
+    }
+    for _, m := range b.CheckEvasions() {
+        if m.Piece != WhiteKing {
+            t.Errorf("Expected only king moves against double check, got %s", m.UCI())
+        }
+    }
+}
+
+func TestCheckEvasions(t *testing.T) {
+    if got := NewBoard().CheckEvasions(); got != nil {
+        t.Errorf("Expected no evasions when not in check, got %v", got)
+    }
+
+    // Bd2 and Qd2 block the check and the king can step to e2 or f1
+    b, err := FromFEN("4k3/8/8/8/1b6/8/5PPP/2BQK2R w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := map[string]bool{"c1d2": true, "d1d2": true, "e1e2": true, "e1f1": true, "c2c3": false}
+    got := make(map[string]bool)
+    for _, m := range b.CheckEvasions() {
+        got[m.UCI()] = true
+    }
+    for move, legal := range want {
+        if got[move] != legal {
+            t.Errorf("Expected %s legal = %v, got %v", move, legal, got[move])
+        }
+    }
+    if len(got) != 4 {
+        t.Errorf("Expected 4 evasions, got %v", got)
+    }
+}
+
+// sameMoves reports whether a and b hold the same moves in any order.
+func sameMoves(a, b []Move) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    seen := make(map[Move]int)
+    for _, m := range a {
+        seen[m]++
+    }
+    for _, m := range b {
+        if seen[m] == 0 {
+            return false
+        }
+        seen[m]--
+    }
+    return true
+}
+
+func TestCheckEvasionsMatchLegalMoves(t *testing.T) {
+    fens := []string{
+        // Double check from the a1 rook and the b4 bishop
+        "4k3/8/8/8/1b6/8/8/R3K2r w - - 0 1",
+        // Double check by a knight and a discovered rook
+        "4k3/8/8/8/8/3n4/8/r3K3 w - - 0 1",
+        // The d2 knight is pinned by the a5 queen and cannot block on e2
+        "4k3/4r3/8/q7/8/8/3N4/4K3 w - - 0 1",
+        // The d2 rook could take the checking knight but is pinned by the
+        // b4 bishop
+        "4k3/8/8/8/1b6/3n4/3R4/4K3 w - - 0 1",
+        // The checking pawn can be taken en passant
+        "8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1",
+        // The e4 pawn is pinned on the e-file and cannot take en passant
+        "8/8/8/4k3/3Pp3/8/8/4R1K1 b - d3 0 1",
+        // A pawn blocks by promoting
+        "2r3k1/1P6/8/8/8/8/8/2K5 w - - 0 1",
+    }
+    check := func(b *Board) {
+        if !b.IsCheck(b.CurrentTurn == Black) {
+            return
+        }
+        if got, want := b.CheckEvasions(), b.LegalMoves(); !sameMoves(got, want) {
+            t.Errorf("%s: evasions %v, legal moves %v", b.FEN(), got, want)
+        }
+    }
+    for _, fen := range fens {
+        b, err := FromFEN(fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if !b.IsCheck(b.CurrentTurn == Black) {
+            t.Errorf("%s: expected the side to move to be in check", fen)
+        }
+        check(b)
+    }
+
+    // Every check reached from the perft positions
+    for _, pos := range perftPositions {
+        b, err := FromFEN(pos.fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        var walk func(depth int)
+        walk = func(depth int) {
+            check(b)
+            if depth == 0 {
+                return
+            }
+            for _, m := range b.LegalMoves() {
+                b.MakeMove(m)
+                walk(depth - 1)
+                b.UnmakeMove()
+            }
+        }
+        walk(2)
+    }
+}
+
+func TestEnPassantDiscoveredCheck(t *testing.T) {
+    // Taking en passant would empty the fifth rank between the king and rook
+    b, err := FromFEN("8/8/8/K2pP2r/8/8/8/4k3 w - d6 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if b.IsValidMove(Move{Start: Position{4, 4}, End: Position{5, 3}}) {
+        t.Error("Expected exd6 to be illegal")
+    }
+    if !b.IsValidMove(Move{Start: Position{4, 4}, End: Position{5, 4}}) {
+        t.Error("Expected e6 to be legal")
+    }
+}
+
+// --- Text rendering ---
+func TestRender(t *testing.T) {
+    b := NewBoard()
+    b.MovePiece(Position{1, 5}, Position{2, 5})
+    b.MovePiece(Position{6, 4}, Position{4, 4})
+    b.MovePiece(Position{1, 6}, Position{3, 6})
+    b.MovePiece(Position{7, 3}, Position{3, 7})
+
+    want := "" +
+        "8  r  n  b [.] k  b  n  r\n" +
+        "7  p  p  p  p  .  p  p  p\n" +
+        "6  .  .  .  .  .  .  .  .\n" +
+        "5  .  .  .  .  p  .  .  .\n" +
+        "4  .  .  .  .  .  .  P [q]\n" +
+        "3  .  .  .  .  .  P  .  .\n" +
+        "2  P  P  P  P  P  .  .  P\n" +
+        "1  R  N  B  Q (K) B  N  R\n" +
+        "   a  b  c  d  e  f  g  h\n"
+    if got := b.Render(RenderOptions{Coordinates: true, LastMove: true, Check: true}); got != want {
+        t.Errorf("Unexpected rendering:\n%s\nwant:\n%s", got, want)
+    }
+}
+
+func TestRenderUnicodeFlipped(t *testing.T) {
+    got := NewBoard().Render(RenderOptions{Unicode: true, Orientation: Black, Coordinates: true})
+    lines := strings.Split(got, "\n")
+    if len(lines) != 10 {
+        t.Fatalf("Expected 9 lines and a trailing newline, got %q", got)
+    }
+    if lines[0] != "1  ♖  ♘  ♗  ♔  ♕  ♗  ♘  ♖" {
+        t.Errorf("Expected White's back rank at the top, got %q", lines[0])
+    }
+    if lines[7] != "8  ♜  ♞  ♝  ♚  ♛  ♝  ♞  ♜" {
+        t.Errorf("Expected Black's back rank at the bottom, got %q", lines[7])
+    }
+    if lines[8] != "   h  g  f  e  d  c  b  a" {
+        t.Errorf("Expected files from h to a, got %q", lines[8])
+    }
+
+    plain := NewBoard().Render(RenderOptions{})
+    if strings.ContainsAny(plain, "18ah") || strings.Count(plain, "\n") != 8 {
+        t.Errorf("Expected no coordinates, got %q", plain)
+    }
+}
+
+// --- Attack maps ---
+func TestAttacks(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/3p4/8/8/1N6/R2QK3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tests := []struct {
+        pos  Position
+        want int
+    }{
+        {Position{0, 0}, 10}, // Rook: a2-a8 and b1-d1, stopping at the queen
+        {Position{1, 1}, 4},  // Knight on b2
+        {Position{0, 3}, 15}, // Queen: stops at the rook, king and d5 pawn
+        {Position{4, 3}, 2},  // Black pawn on d5 attacks c4 and e4
+        {Position{0, 4}, 5},  // King on e1
+        {Position{3, 3}, 0},  // Empty square
+    }
+    for _, tt := range tests {
+        if got := b.Attacks(tt.pos); got.Count() != tt.want {
+            t.Errorf("Expected %d attacked squares from %s, got %v", tt.want, tt.pos, got.Positions())
+        }
+    }
+    if got := b.Attacks(Position{4, 3}); !got.Has(Position{3, 2}) || !got.Has(Position{3, 4}) {
+        t.Errorf("Expected the d5 pawn to attack c4 and e4, got %v", got.Positions())
+    }
+}
@@ -0,0 +1,136 @@
+package board
+
+import "strings"
+
+// CastlingRights is a bitmask of the castling moves each side may still make.
+type CastlingRights uint8
+
+const (
+    WhiteKingside CastlingRights = 1 << iota
+    WhiteQueenside
+    BlackKingside
+    BlackQueenside
+
+    NoCastling  CastlingRights = 0
+    AllCastling                = WhiteKingside | WhiteQueenside | BlackKingside | BlackQueenside
+)
+
+// Has reports whether every right in r is set.
+func (c CastlingRights) Has(r CastlingRights) bool {
+    return c&r == r
+}
+
+// String returns the rights as in a FEN castling field, such as "KQkq" or "-".
+func (c CastlingRights) String() string {
+    var sb strings.Builder
+    for i, r := range [4]CastlingRights{WhiteKingside, WhiteQueenside, BlackKingside, BlackQueenside} {
+        if c.Has(r) {
+            sb.WriteByte("KQkq"[i])
+        }
+    }
+    if sb.Len() == 0 {
+        return "-"
+    }
+    return sb.String()
+}
+
+// kingsideRight and queensideRight return the castling right of one color.
+func kingsideRight(isBlack bool) CastlingRights {
+    if isBlack {
+        return BlackKingside
+    }
+    return WhiteKingside
+}
+
+func queensideRight(isBlack bool) CastlingRights {
+    if isBlack {
+        return BlackQueenside
+    }
+    return WhiteQueenside
+}
+
+// castlingRight returns the right for a color index (0 for White, 1 for
+// Black) and side (0 for kingside, 1 for queenside).
+func castlingRight(color, side int) CastlingRights {
+    return WhiteKingside << uint(2*color+side)
+}
+
+// castlingRooks holds the file of the rook each side castles with, indexed by
+// color index and then by side: 0 for kingside, 1 for queenside.
+type castlingRooks [2][2]int
+
+var standardRooks = castlingRooks{{7, 0}, {7, 0}}
+
+// lost returns the rights that are lost once piece moves from start to end:
+// moving the king loses both of its side's rights, and moving a castling
+// rook, or capturing it on its square, loses that rook's right.
+func (r castlingRooks) lost(piece Piece, start, end Position) CastlingRights {
+    var lost CastlingRights
+    for color := 0; color < 2; color++ {
+        row := homeRow(color == 1)
+        for side := 0; side < 2; side++ {
+            rook := Position{row, r[color][side]}
+            if (piece.Type() == King && colorIndex(piece.Color()) == color) || start == rook || end == rook {
+                lost |= castlingRight(color, side)
+            }
+        }
+    }
+    return lost
+}
+
+// castling describes how the king and rook move when castling.
+type castling struct {
+    kingFrom, kingTo Position
+    rookFrom, rookTo Position
+}
+
+// castlingFor returns the castling of the king on kingPos towards one side.
+// Whatever the starting files, the king ends on the g- or c-file and the rook
+// next to it on the f- or d-file.
+func castlingFor(rooks castlingRooks, kingPos Position, isBlack, kingside bool) castling {
+    side := 0
+    c := castling{kingFrom: kingPos, kingTo: Position{kingPos.Row, 6}, rookTo: Position{kingPos.Row, 5}}
+    if !kingside {
+        side = 1
+        c.kingTo.Col, c.rookTo.Col = 2, 3
+    }
+    c.rookFrom = Position{kingPos.Row, rooks[colorIndex(colorOf(isBlack))][side]}
+    return c
+}
+
+// span returns the first and last file touched by the king and rook.
+func (c castling) span() (from, to int) {
+    from, to = c.kingFrom.Col, c.kingFrom.Col
+    for _, col := range [3]int{c.kingTo.Col, c.rookFrom.Col, c.rookTo.Col} {
+        from, to = min(from, col), max(to, col)
+    }
+    return from, to
+}
+
+// rooks returns the castling rook files in use: the a- and h-files unless
+// the board plays Chess960.
+func (b *Board) rooks() castlingRooks {
+    if b.Chess960 {
+        return b.rookFiles
+    }
+    return standardRooks
+}
+
+// castlingOf reports whether move is a castling move in the current position
+// and how the pieces move. Standard castling is written as the king moving
+// two squares. In Chess960 the king may start on any file, even next to its
+// destination, so castling is written as the king capturing its own rook.
+func (b *Board) castlingOf(move Move) (castling, bool) {
+    piece := b.GetPieceAt(move.Start)
+    isBlack := piece.Color() == Black
+    if piece.Type() != King || move.Start.Row != homeRow(isBlack) || move.End.Row != move.Start.Row {
+        return castling{}, false
+    }
+
+    kingside := move.End.Col > move.Start.Col
+    c := castlingFor(b.rooks(), move.Start, isBlack, kingside)
+    if b.Chess960 {
+        return c, move.End == c.rookFrom && b.GetPieceAt(move.End) == NewPiece(Rook, piece.Color())
+    }
+    return c, abs(move.End.Col-move.Start.Col) == 2
+}
@@ -0,0 +1,74 @@
+package board
+
+import "fmt"
+
+// chess960Knights lists the placements of the two knights among the five
+// squares left after the bishops and queen, in Scharnagl numbering.
+var chess960Knights = [10][2]int{
+    {0, 1}, {0, 2}, {0, 3}, {0, 4}, {1, 2},
+    {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4},
+}
+
+// NewChess960Board returns the Chess960 start position with the given
+// Scharnagl number from 0 to 959. Number 518 is the standard setup.
+func NewChess960Board(n int) (*Board, error) {
+    if n < 0 || n > 959 {
+        return nil, fmt.Errorf("board: Chess960 position %d is not between 0 and 959", n)
+    }
+
+    var rank [8]PieceType
+    rank[2*(n%4)+1] = Bishop // Light-squared bishop on b, d, f or h
+    n /= 4
+    rank[2*(n%4)] = Bishop // Dark-squared bishop on a, c, e or g
+    n /= 4
+
+    // The remaining pieces fill the empty files from left to right
+    place := func(pieceType PieceType, skip int) {
+        for col := range rank {
+            if rank[col] != NoPieceType {
+                continue
+            }
+            if skip == 0 {
+                rank[col] = pieceType
+                return
+            }
+            skip--
+        }
+    }
+    place(Queen, n%6)
+    knights := chess960Knights[n/6]
+    place(Knight, knights[1])
+    place(Knight, knights[0])
+    place(Rook, 0)
+    place(King, 0)
+    place(Rook, 0)
+
+    b := &Board{
+        CurrentTurn: White,
+        Castling:    AllCastling,
+        Chess960:    true,
+        EnPassant:   NoPosition,
+    }
+    kingPlaced := false
+    for col, pieceType := range rank {
+        b.Squares[0][col] = NewPiece(pieceType, White)
+        b.Squares[1][col] = WhitePawn
+        b.Squares[6][col] = BlackPawn
+        b.Squares[7][col] = NewPiece(pieceType, Black)
+
+        switch pieceType {
+        case King:
+            kingPlaced = true
+        case Rook:
+            side := 1 // The rook left of the king castles queenside
+            if kingPlaced {
+                side = 0
+            }
+            b.rookFiles[0][side], b.rookFiles[1][side] = col, col
+        }
+    }
+    b.syncBitboards()
+    b.hash = b.computeHash()
+    b.pawnHash = b.computePawnHash()
+    return b, nil
+}
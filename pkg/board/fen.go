@@ -0,0 +1,269 @@
+package board
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// StartFEN is the FEN of the standard starting position.
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// FromFEN parses a position in Forsyth-Edwards Notation. The halfmove clock
+// and fullmove number may be omitted, in which case they default to 0 and 1.
+//
+// The castling field may also be given in Shredder-FEN, naming the file of
+// each castling rook ("HAha"), or X-FEN, which uses file letters only when
+// the rook is not the outermost one. Castling rights that need a king off
+// the e-file or rooks off the corners switch the board to Chess960.
+func FromFEN(fen string) (*Board, error) {
+    fields := strings.Fields(fen)
+    if len(fields) != 4 && len(fields) != 6 {
+        return nil, fmt.Errorf("fen: expected 4 or 6 fields, got %d", len(fields))
+    }
+
+    b := &Board{EnPassant: NoPosition}
+    if err := b.parsePlacement(fields[0]); err != nil {
+        return nil, err
+    }
+    b.syncBitboards()
+
+    switch fields[1] {
+    case "w":
+        b.CurrentTurn = White
+    case "b":
+        b.CurrentTurn = Black
+    default:
+        return nil, fmt.Errorf("fen: invalid side to move %q, want \"w\" or \"b\"", fields[1])
+    }
+
+    if err := b.parseCastling(fields[2]); err != nil {
+        return nil, err
+    }
+    if err := b.parseEnPassant(fields[3]); err != nil {
+        return nil, err
+    }
+
+    halfMoves, fullMoves := 0, 1
+    if len(fields) == 6 {
+        var err error
+        halfMoves, err = strconv.Atoi(fields[4])
+        if err != nil || halfMoves < 0 {
+            return nil, fmt.Errorf("fen: invalid halfmove clock %q", fields[4])
+        }
+        fullMoves, err = strconv.Atoi(fields[5])
+        if err != nil || fullMoves < 1 {
+            return nil, fmt.Errorf("fen: invalid fullmove number %q", fields[5])
+        }
+    }
+    b.FiftyMoveCount = halfMoves
+    b.HalfMoveClock = halfMoves
+    b.MoveCount = 2 * (fullMoves - 1)
+    if b.CurrentTurn == Black {
+        b.MoveCount++
+    }
+
+    b.hash = b.computeHash()
+    b.pawnHash = b.computePawnHash()
+    return b, nil
+}
+
+// FEN returns the position in Forsyth-Edwards Notation.
+func (b *Board) FEN() string {
+    var sb strings.Builder
+
+    for row := 7; row >= 0; row-- {
+        empty := 0
+        for col := 0; col < 8; col++ {
+            piece := b.Squares[row][col]
+            if piece == NoPiece {
+                empty++
+                continue
+            }
+            if empty > 0 {
+                sb.WriteByte(byte('0' + empty))
+                empty = 0
+            }
+            sb.WriteByte(pieceChar(piece))
+        }
+        if empty > 0 {
+            sb.WriteByte(byte('0' + empty))
+        }
+        if row > 0 {
+            sb.WriteByte('/')
+        }
+    }
+
+    if b.CurrentTurn == Black {
+        sb.WriteString(" b ")
+    } else {
+        sb.WriteString(" w ")
+    }
+
+    sb.WriteString(b.castlingField())
+    sb.WriteByte(' ')
+
+    sb.WriteString(b.EnPassant.String())
+
+    fmt.Fprintf(&sb, " %d %d", b.FiftyMoveCount, b.MoveCount/2+1)
+    return sb.String()
+}
+
+func (b *Board) parsePlacement(placement string) error {
+    ranks := strings.Split(placement, "/")
+    if len(ranks) != 8 {
+        return fmt.Errorf("fen: expected 8 ranks, got %d", len(ranks))
+    }
+
+    kings := map[Piece]int{}
+    for i, rank := range ranks {
+        row := 7 - i
+        col := 0
+        for j := 0; j < len(rank); j++ {
+            c := rank[j]
+            if c >= '1' && c <= '8' {
+                col += int(c - '0')
+                continue
+            }
+            piece, ok := pieceChars[c]
+            if !ok {
+                return fmt.Errorf("fen: invalid piece %q on rank %d", c, row+1)
+            }
+            if col >= 8 {
+                return fmt.Errorf("fen: rank %d has more than 8 files", row+1)
+            }
+            if piece.Type() == Pawn && (row == 0 || row == 7) {
+                return fmt.Errorf("fen: pawn on rank %d", row+1)
+            }
+            if piece.Type() == King {
+                kings[piece]++
+            }
+            b.Squares[row][col] = piece
+            col++
+        }
+        if col != 8 {
+            return fmt.Errorf("fen: rank %d has %d files, want 8", row+1, col)
+        }
+    }
+
+    if kings[WhiteKing] != 1 || kings[BlackKing] != 1 {
+        return fmt.Errorf("fen: each side needs exactly one king, got %d white and %d black",
+            kings[WhiteKing], kings[BlackKing])
+    }
+    return nil
+}
+
+func (b *Board) parseCastling(castling string) error {
+    b.Castling = NoCastling
+    b.rookFiles = standardRooks
+    if castling == "-" {
+        return nil
+    }
+
+    for i := 0; i < len(castling); i++ {
+        c := castling[i]
+        isBlack := c >= 'a' && c <= 'z'
+        color := colorIndex(colorOf(isBlack))
+        king := b.findKing(isBlack)
+        if king.Row != homeRow(isBlack) {
+            return fmt.Errorf("fen: castling rights %q without a king on its back rank", castling)
+        }
+
+        var file int
+        switch upper := c &^ ('a' - 'A'); {
+        case upper == 'K':
+            file = b.outermostRook(king, isBlack, 7)
+        case upper == 'Q':
+            file = b.outermostRook(king, isBlack, 0)
+        case upper >= 'A' && upper <= 'H':
+            file = int(upper - 'A')
+        default:
+            return fmt.Errorf("fen: invalid castling rights %q", castling)
+        }
+        if file == king.Col {
+            return fmt.Errorf("fen: castling rights %q name the king's own file", castling)
+        }
+        if b.GetPieceAt(Position{king.Row, file}) != NewPiece(Rook, colorOf(isBlack)) {
+            return fmt.Errorf("fen: castling rights %q without a rook on the %c-file", castling, 'a'+file)
+        }
+
+        side := 0
+        if file < king.Col {
+            side = 1
+        }
+        b.Castling |= castlingRight(color, side)
+        b.rookFiles[color][side] = file
+        if king.Col != 4 || file != standardRooks[color][side] {
+            b.Chess960 = true
+        }
+    }
+    return nil
+}
+
+// outermostRook returns the file of the rook furthest from the king towards
+// the edge file, or the edge file itself if there is no rook.
+func (b *Board) outermostRook(king Position, isBlack bool, edge int) int {
+    rook := NewPiece(Rook, colorOf(isBlack))
+    step := sign(king.Col - edge)
+    for col := edge; col != king.Col; col += step {
+        if b.GetPieceAt(Position{king.Row, col}) == rook {
+            return col
+        }
+    }
+    return edge
+}
+
+// castlingField returns the FEN castling field. Chess960 positions use X-FEN,
+// so the field only differs from standard FEN when a castling rook has
+// another rook further out on the same side.
+func (b *Board) castlingField() string {
+    if !b.Chess960 || b.Castling == NoCastling {
+        return b.Castling.String()
+    }
+
+    var sb strings.Builder
+    for color := 0; color < 2; color++ {
+        isBlack := color == 1
+        king := b.findKing(isBlack)
+        for side, edge := range [2]int{7, 0} {
+            if !b.Castling.Has(castlingRight(color, side)) {
+                continue
+            }
+            c := "KQ"[side]
+            if file := b.rookFiles[color][side]; file != b.outermostRook(king, isBlack, edge) {
+                c = byte('A' + file)
+            }
+            if isBlack {
+                c += 'a' - 'A'
+            }
+            sb.WriteByte(c)
+        }
+    }
+    return sb.String()
+}
+
+func (b *Board) parseEnPassant(field string) error {
+    if field == "-" {
+        return nil
+    }
+
+    target, err := ParsePosition(field)
+    if err != nil {
+        return fmt.Errorf("fen: invalid en passant square %q", field)
+    }
+
+    pawnRow, targetRow := 3, 2 // White has just pushed
+    if b.CurrentTurn == White {
+        pawnRow, targetRow = 4, 5
+    }
+    if target.Row != targetRow {
+        return fmt.Errorf("fen: en passant square %s is not on rank %d", field, targetRow+1)
+    }
+
+    pawn := NewPiece(Pawn, b.CurrentTurn.Opponent())
+    if b.Squares[pawnRow][target.Col] != pawn {
+        return fmt.Errorf("fen: no pawn in front of en passant square %s", field)
+    }
+    b.EnPassant = target
+    return nil
+}
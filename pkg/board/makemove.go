@@ -0,0 +1,123 @@
+package board
+
+// undoState holds everything MakeMove changes that cannot be recomputed from
+// the move itself, so UnmakeMove can restore the previous position exactly.
+type undoState struct {
+    move           Move
+    captured       Piece
+    capturedPos    Position
+    castling       CastlingRights
+    enPassant      Position
+    lastMove       Move
+    fiftyMoveCount int
+    hash           uint64
+    pawnHash       uint64
+    castle         castling
+    castled        bool
+    ending         Outcome
+}
+
+// MakeMove plays move on the board and pushes it onto the undo history. The
+// move is not validated; use IsValidMove or LegalMoves to obtain legal moves.
+func (b *Board) MakeMove(move Move) {
+    b.sync() // The hash is saved for UnmakeMove before anything moves
+    piece := b.GetPieceAt(move.Start)
+    move.Piece = piece
+
+    castle, castled := b.castlingOf(move)
+    captured, capturedPos := b.GetPieceAt(move.End), move.End
+    if castled {
+        captured = NoPiece // In Chess960 the king "captures" its own rook
+    } else if piece.Type() == Pawn && move.Start.Col != move.End.Col && captured == NoPiece {
+        capturedPos = Position{move.Start.Row, move.End.Col} // En passant
+        captured = b.GetPieceAt(capturedPos)
+    }
+
+    state := undoState{
+        move:           move,
+        captured:       captured,
+        capturedPos:    capturedPos,
+        castling:       b.Castling,
+        enPassant:      b.EnPassant,
+        lastMove:       b.LastMove,
+        fiftyMoveCount: b.FiftyMoveCount,
+        hash:           b.hash,
+        pawnHash:       b.pawnHash,
+        castle:         castle,
+        castled:        castled,
+        ending:         b.ending,
+    }
+
+    // Take out the old castling and en passant keys; applyMove updates the
+    // hashes for the pieces that move
+    b.hash ^= zobristCastling[b.Castling] ^ b.enPassantKey()
+    b.applyMove(move)
+    b.Castling &^= b.rooks().lost(piece, move.Start, move.End)
+
+    // A double pawn push lets the opponent capture en passant
+    b.EnPassant = NoPosition
+    if piece.Type() == Pawn && abs(move.End.Row-move.Start.Row) == 2 {
+        b.EnPassant = Position{(move.Start.Row + move.End.Row) / 2, move.Start.Col}
+    }
+
+    b.MoveCount++
+
+    // Update fifty-move rule
+    if piece.Type() != Pawn && captured == NoPiece {
+        b.FiftyMoveCount++
+    } else {
+        b.FiftyMoveCount = 0
+    }
+    b.HalfMoveClock = b.FiftyMoveCount
+
+    b.LastMove = move
+    b.CurrentTurn = b.CurrentTurn.Opponent()
+
+    b.hash ^= zobristBlack ^ zobristCastling[b.Castling] ^ b.enPassantKey()
+    b.history = append(b.history, state)
+}
+
+// UnmakeMove takes back the last move made with MakeMove and returns it. It
+// reports false if there is no move to take back.
+func (b *Board) UnmakeMove() (Move, bool) {
+    if len(b.history) == 0 {
+        return Move{}, false
+    }
+    state := b.history[len(b.history)-1]
+    b.history = b.history[:len(b.history)-1]
+    move := state.move
+
+    if c := state.castle; state.castled {
+        rook := b.GetPieceAt(c.rookTo)
+        b.SetPieceAt(c.kingTo, NoPiece)
+        b.SetPieceAt(c.rookTo, NoPiece)
+        b.SetPieceAt(c.kingFrom, move.Piece)
+        b.SetPieceAt(c.rookFrom, rook)
+    } else {
+        b.SetPieceAt(move.End, NoPiece)
+        b.SetPieceAt(move.Start, move.Piece)
+        b.SetPieceAt(state.capturedPos, state.captured)
+    }
+
+    b.Castling = state.castling
+    b.EnPassant = state.enPassant
+    b.LastMove = state.lastMove
+    b.FiftyMoveCount = state.fiftyMoveCount
+    b.HalfMoveClock = state.fiftyMoveCount
+    b.hash = state.hash
+    b.pawnHash = state.pawnHash
+    b.ending = state.ending
+    b.MoveCount--
+    b.CurrentTurn = b.CurrentTurn.Opponent()
+
+    return move, true
+}
+
+// History returns the moves made on the board, oldest first.
+func (b *Board) History() []Move {
+    moves := make([]Move, len(b.history))
+    for i, state := range b.history {
+        moves[i] = state.move
+    }
+    return moves
+}
@@ -1,167 +1,108 @@
 package board
 
-import "fmt"
-
-func (b *Board) MovePiece(start, end Position) bool {
-    piece := b.GetPieceAt(start)
-    if piece == 0 {
-        return false // No piece to move
-    }
-
-    if !isWithinBounds(start) || !isWithinBounds(end) {
-        return false // Move out of bounds
-    }
-
-    // Ensure the piece belongs to the current player
-    if (piece&White == 0 || b.CurrentTurn == White) && (piece&Black == 0 || b.CurrentTurn == Black) {
-        return true // It's valid to move the piece
+import "errors"
+
+var (
+    ErrOutOfBounds       = errors.New("board: square is off the board")
+    ErrNoPiece           = errors.New("board: no piece on the start square")
+    ErrNotYourTurn       = errors.New("board: piece does not belong to the side to move")
+    ErrIllegalPattern    = errors.New("board: piece cannot move that way")
+    ErrLeavesKingInCheck = errors.New("board: move leaves the king in check")
+    ErrPromotionRequired = errors.New("board: pawn move to the last rank needs a promotion piece")
+    ErrInvalidPromotion  = errors.New("board: pawns promote to a queen, rook, bishop or knight on the last rank")
+)
+
+// MovePiece moves the piece at start to end if that is legal. A pawn reaching
+// the last rank promotes to the optional promotion piece type, or to a queen
+// if none is given. Use TryMove to find out why a move was rejected.
+func (b *Board) MovePiece(start, end Position, promotion ...PieceType) bool {
+    move := Move{Start: start, End: end}
+    if len(promotion) > 0 {
+        move.Promotion = promotion[0]
+    } else if isWithinBounds(start) && isWithinBounds(end) && b.GetPieceAt(start).Type() == Pawn && (end.Row == 7 || end.Row == 0) {
+        move.Promotion = Queen
     }
+    return b.TryMove(move) == nil
+}
 
-    destPiece := b.GetPieceAt(end)
-    if destPiece != 0 && (destPiece&White) == (piece&White) {
-        return false // Can't capture your own piece
+// TryMove plays move if it is legal for the side to move. Otherwise the board
+// is left untouched and one of the Err* errors explains why the move failed.
+func (b *Board) TryMove(move Move) error {
+    candidate, err := b.validateMove(move)
+    if err != nil {
+        return err
     }
+    b.MakeMove(candidate)
+    return nil
+}
 
-    if !b.isLegalMove(piece, start, end) {
-        return false // The move is not legal for this piece
+// validateMove checks move against the legal moves of the side to move and
+// returns the matching generated move, or the error TryMove reports.
+func (b *Board) validateMove(move Move) (Move, error) {
+    if !isWithinBounds(move.Start) || !isWithinBounds(move.End) {
+        return Move{}, ErrOutOfBounds
     }
 
-    // Perform the move
-    b.Squares[end.Row][end.Col] = piece
-    b.Squares[start.Row][start.Col] = 0
-
-    // Check for pawn promotion
-    if piece&Pawn != 0 {
-        if (piece&White != 0 && end.Row == 7) || (piece&Black != 0 && end.Row == 0) {
-            b.Squares[end.Row][end.Col] = Queen | (piece & (White | Black)) // Promote to Queen
-        }
+    piece := b.GetPieceAt(move.Start)
+    if piece == NoPiece {
+        return Move{}, ErrNoPiece
     }
-
-    // Update position history
-    key := fmt.Sprintf("%v", b.Squares)
-    b.PositionHistory[key]++
-
-    b.MoveCount++
-
-    // Update fifty-move rule
-    if (piece & 0b111) != Pawn && destPiece == 0 {
-        b.FiftyMoveCount++
-    } else {
-        b.FiftyMoveCount = 0
+    if piece.Color() != b.CurrentTurn {
+        return Move{}, ErrNotYourTurn
     }
-
-    // Update last move
-    b.LastMove = Move{Start: start, End: end, Piece: piece}
-
-    if b.CurrentTurn == White {
-        b.CurrentTurn = Black
-    } else {
-        b.CurrentTurn = White
+    if move.Promotion != NoPieceType && !isPromotionPiece(move.Promotion) {
+        return Move{}, ErrInvalidPromotion
     }
 
-    return true
-}
-
-
-
-func (b *Board) isLegalMove(piece int, start, end Position) bool {
-    pieceType := piece & 0b111
-
-    switch pieceType {
-    case Rook:
-        return (start.Row == end.Row || start.Col == end.Col) && b.isPathClear(start, end)
-    case Knight:
-        rowDiff := abs(start.Row - end.Row)
-        colDiff := abs(start.Col - end.Col)
-        return (rowDiff == 2 && colDiff == 1) || (rowDiff == 1 && colDiff == 2)
-    case Bishop:
-        return abs(start.Row-end.Row) == abs(start.Col-end.Col) && b.isPathClear(start, end)
-    case Queen:
-        return ((start.Row == end.Row || start.Col == end.Col) || abs(start.Row-end.Row) == abs(start.Col-end.Col)) && b.isPathClear(start, end)
-    case King:
-        if abs(start.Row-end.Row) <= 1 && abs(start.Col-end.Col) <= 1 {
-            return true
+    var candidate Move
+    found := false
+    for _, m := range b.pieceMoves(move.Start) {
+        if m.End != move.End {
+            continue
         }
-
-        isBlack := piece&Black != 0
-        if start.Row == end.Row && abs(start.Col-end.Col) == 2 {
-            intermediateCol := (start.Col + end.Col) / 2
-            intermediatePos := Position{Row: start.Row, Col: intermediateCol}
-            if b.IsCheck(isBlack) || b.IsCheckAfterMove(intermediatePos, isBlack) {
-                return false
-            }
-            if end.Col == 6 {
-                return (isBlack && start.Row == 7 && b.canCastleKingside(true)) || (!isBlack && start.Row == 0 && b.canCastleKingside(false))
-            }
-            if end.Col == 2 {
-                return (isBlack && start.Row == 7 && b.canCastleQueenside(true)) || (!isBlack && start.Row == 0 && b.canCastleQueenside(false))
-            }
+        if m.Promotion != NoPieceType && move.Promotion == NoPieceType {
+            return Move{}, ErrPromotionRequired
         }
-        return false
-    case Pawn:
-        direction := 1
-        if piece&Black != 0 {
-            direction = -1
+        if m.Promotion == NoPieceType && move.Promotion != NoPieceType {
+            return Move{}, ErrInvalidPromotion
         }
-
-        if start.Col == end.Col && b.IsEmpty(end) {
-            if start.Row+direction == end.Row {
-                return true
-            }
-            if (start.Row == 1 && piece&White != 0 || start.Row == 6 && piece&Black != 0) && start.Row+2*direction == end.Row && b.IsEmpty(Position{start.Row + direction, start.Col}) {
-                return true
-            }
-        }
-
-        if abs(start.Col-end.Col) == 1 && start.Row+direction == end.Row && !b.IsEmpty(end) && (b.GetPieceAt(end)&White != piece&White) {
-            return true
+        if m.Promotion == move.Promotion {
+            candidate, found = m, true
+            break
         }
-
-        return b.canCaptureEnPassant(start, end, piece&Black != 0)
-    default:
-        return false
     }
+    if !found {
+        return Move{}, ErrIllegalPattern
+    }
+    if !b.isLegal(candidate) {
+        return Move{}, ErrLeavesKingInCheck
+    }
+    return candidate, nil
 }
 
-
+// IsCheckAfterMove reports whether the king of the given color would be in
+// check after moving to pos. Use IsValidMove to test the moves of other
+// pieces.
 func (b *Board) IsCheckAfterMove(pos Position, isBlack bool) bool {
-    // Create a temporary copy of the board
-    tempBoard := *b
-
-    // Simulate the king's move to the new position
-    king := King
-    if isBlack {
-        king |= Black
-    } else {
-        king |= White
-    }
-
-    for row := 0; row < 8; row++ {
-        for col := 0; col < 8; col++ {
-            if tempBoard.Squares[row][col] == king {
-                tempBoard.Squares[row][col] = 0
-            }
-        }
+    kingPos := b.findKing(isBlack)
+    if !isWithinBounds(kingPos) || !isWithinBounds(pos) {
+        return false
     }
-    tempBoard.Squares[pos.Row][pos.Col] = king
-
-    // Check if the king is in check in the new position
-    return tempBoard.IsCheck(isBlack)
+    king := b.GetPieceAt(kingPos)
+    return !b.kingSafety(king.Color()).allows(b, Move{Start: kingPos, End: pos, Piece: king})
 }
 
+// UndoMove takes back move, which must be the last move made on the board.
+//
+// Deprecated: use UnmakeMove.
 func (b *Board) UndoMove(move Move) {
-    b.Squares[move.Start.Row][move.Start.Col] = move.Piece
-    b.Squares[move.End.Row][move.End.Col] = 0
-}
-
-func (b *Board) IsValidMove(move Move) bool {
-    // Check if the move is valid
-    return true
-}
-
-func (b *Board) GenerateMoves(pos Position) []Position {
-    // Get all possible moves for the piece at the given position
-    return nil
+    if len(b.history) == 0 {
+        return
+    }
+    last := b.history[len(b.history)-1].move
+    if last.Start == move.Start && last.End == move.End {
+        b.UnmakeMove()
+    }
 }
 
 func isWithinBounds(pos Position) bool {
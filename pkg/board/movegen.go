@@ -0,0 +1,217 @@
+package board
+
+var (
+    knightOffsets = [8][2]int{{2, 1}, {1, 2}, {-1, 2}, {-2, 1}, {-2, -1}, {-1, -2}, {1, -2}, {2, -1}}
+    kingOffsets   = [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+
+    promotionPieces = [4]PieceType{Queen, Rook, Bishop, Knight}
+)
+
+// LegalMoves returns every legal move for the side to move. Pawn moves to the
+// last rank are returned once per promotion piece.
+func (b *Board) LegalMoves() []Move {
+    return b.legalMoves(b.CurrentTurn == Black)
+}
+
+// GenerateMoves returns the squares the piece at pos can legally move to.
+// The piece's own color is used, so this also works when it is not that
+// side's turn.
+func (b *Board) GenerateMoves(pos Position) []Position {
+    if !isWithinBounds(pos) || b.IsEmpty(pos) {
+        return nil
+    }
+
+    safety := b.kingSafety(b.GetPieceAt(pos).Color())
+    var targets []Position
+    for _, move := range b.pieceMoves(pos) {
+        if !safety.allows(b, move) {
+            continue
+        }
+        // Promotions produce several moves to the same square
+        if move.Promotion != NoPieceType && move.Promotion != Queen {
+            continue
+        }
+        targets = append(targets, move.End)
+    }
+    return targets
+}
+
+// IsValidMove reports whether move is legal for the piece on its start square.
+// A pawn reaching the last rank must name its promotion piece.
+func (b *Board) IsValidMove(move Move) bool {
+    if !isWithinBounds(move.Start) || !isWithinBounds(move.End) || b.IsEmpty(move.Start) {
+        return false
+    }
+    for _, m := range b.pieceMoves(move.Start) {
+        if m.End == move.End && m.Promotion == move.Promotion {
+            return b.isLegal(m)
+        }
+    }
+    return false
+}
+
+// legalMoves returns every legal move for the given color.
+func (b *Board) legalMoves(isBlack bool) []Move {
+    color := colorOf(isBlack)
+    safety := b.kingSafety(color)
+    var moves []Move
+    for pieces := b.pieceBitboards().Colors[colorIndex(color)]; pieces != 0; {
+        moves = b.appendPieceMoves(moves, pieces.popLSB().Position())
+    }
+
+    // Drop the moves that leave the king in check, in place
+    legal := moves[:0]
+    for _, move := range moves {
+        if safety.allows(b, move) {
+            legal = append(legal, move)
+        }
+    }
+    return legal
+}
+
+// hasLegalMove reports whether the given color has at least one legal move.
+func (b *Board) hasLegalMove(isBlack bool) bool {
+    color := colorOf(isBlack)
+    safety := b.kingSafety(color)
+    var buf [32]Move
+    for pieces := b.pieceBitboards().Colors[colorIndex(color)]; pieces != 0; {
+        for _, move := range b.appendPieceMoves(buf[:0], pieces.popLSB().Position()) {
+            if safety.allows(b, move) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// isLegal reports whether a pseudo-legal move leaves the mover's king safe.
+func (b *Board) isLegal(move Move) bool {
+    return b.kingSafety(move.Piece.Color()).allows(b, move)
+}
+
+// pieceMoves returns the pseudo-legal moves of the piece at pos. Castling is
+// only generated when it is fully legal; every other move may still leave
+// the king in check.
+func (b *Board) pieceMoves(pos Position) []Move {
+    return b.appendPieceMoves(nil, pos)
+}
+
+// appendPieceMoves appends the pseudo-legal moves of the piece at pos to
+// moves. Pieces other than pawns move to the squares they attack that do not
+// hold a piece of their own side.
+func (b *Board) appendPieceMoves(moves []Move, pos Position) []Move {
+    piece := b.GetPieceAt(pos)
+    switch piece.Type() {
+    case NoPieceType:
+        return moves
+    case Pawn:
+        return b.appendPawnMoves(moves, pos, piece)
+    }
+
+    p := b.pieceBitboards()
+    targets := p.Attacks(pos.Square()) &^ p.Colors[colorIndex(piece.Color())]
+    for targets != 0 {
+        moves = append(moves, Move{Start: pos, End: targets.popLSB().Position(), Piece: piece})
+    }
+    if piece.Type() == King {
+        moves = append(moves, b.castlingMoves(pos, piece)...)
+    }
+    return moves
+}
+
+func (b *Board) appendPawnMoves(moves []Move, pos Position, piece Piece) []Move {
+    isBlack := piece.Color() == Black
+    dir := direction(isBlack)
+    startRow, lastRow := 1, 7
+    if isBlack {
+        startRow, lastRow = 6, 0
+    }
+
+    add := func(end Position) {
+        if end.Row == lastRow {
+            for _, promotion := range promotionPieces {
+                moves = append(moves, Move{Start: pos, End: end, Piece: piece, Promotion: promotion})
+            }
+            return
+        }
+        moves = append(moves, Move{Start: pos, End: end, Piece: piece})
+    }
+
+    oneStep := Position{pos.Row + dir, pos.Col}
+    if isWithinBounds(oneStep) && b.IsEmpty(oneStep) {
+        add(oneStep)
+        twoStep := Position{pos.Row + 2*dir, pos.Col}
+        if pos.Row == startRow && b.IsEmpty(twoStep) {
+            add(twoStep)
+        }
+    }
+
+    for _, dc := range [2]int{-1, 1} {
+        end := Position{pos.Row + dir, pos.Col + dc}
+        if !isWithinBounds(end) {
+            continue
+        }
+        target := b.GetPieceAt(end)
+        if target != NoPiece && b.isEnemyPiece(target, isBlack) {
+            add(end)
+        } else if target == NoPiece && b.canCaptureEnPassant(pos, end, isBlack) {
+            add(end)
+        }
+    }
+    return moves
+}
+
+// castlingMoves returns the castling moves available to the king at pos. The
+// king may not castle out of, through or into check.
+func (b *Board) castlingMoves(pos Position, piece Piece) []Move {
+    isBlack := piece.Color() == Black
+    if pos.Row != homeRow(isBlack) {
+        return nil
+    }
+
+    var moves []Move
+    for _, kingside := range [2]bool{true, false} {
+        if !b.canCastle(isBlack, kingside) {
+            continue
+        }
+        c := castlingFor(b.rooks(), pos, isBlack, kingside)
+        if !b.pieceBitboards().castlingSafe(c, colorIndex(piece.Color())) {
+            continue
+        }
+
+        end := c.kingTo
+        if b.Chess960 {
+            end = c.rookFrom
+        }
+        moves = append(moves, Move{Start: pos, End: end, Piece: piece})
+    }
+    return moves
+}
+
+// applyMove moves pieces on the squares only, including the rook during
+// castling, the captured pawn during en passant and the promoted piece. No
+// other game state is touched.
+func (b *Board) applyMove(move Move) {
+    piece := b.GetPieceAt(move.Start)
+    pieceType := piece.Type()
+
+    if pieceType == Pawn && move.Start.Col != move.End.Col && b.IsEmpty(move.End) {
+        b.SetPieceAt(Position{move.Start.Row, move.End.Col}, NoPiece) // En passant capture
+    }
+
+    if c, ok := b.castlingOf(move); ok {
+        // Lift both pieces first, since in Chess960 they may swap squares
+        rook := b.GetPieceAt(c.rookFrom)
+        b.SetPieceAt(c.kingFrom, NoPiece)
+        b.SetPieceAt(c.rookFrom, NoPiece)
+        b.SetPieceAt(c.kingTo, piece)
+        b.SetPieceAt(c.rookTo, rook)
+        return
+    }
+
+    if move.Promotion != NoPieceType {
+        piece = NewPiece(move.Promotion, piece.Color())
+    }
+    b.SetPieceAt(move.End, piece)
+    b.SetPieceAt(move.Start, NoPiece)
+}
@@ -0,0 +1,208 @@
+package board
+
+import "errors"
+
+var (
+    ErrGameOver      = errors.New("board: the game is already over")
+    ErrNoDrawToClaim = errors.New("board: neither the 50-move rule nor threefold repetition applies")
+)
+
+// Result is a game result as written in PGN.
+type Result string
+
+const (
+    WhiteWon   Result = "1-0"
+    BlackWon   Result = "0-1"
+    DrawResult Result = "1/2-1/2"
+    NoResult   Result = "*" // The game is still in progress
+)
+
+// Termination is the reason a game ended.
+type Termination int
+
+const (
+    NotTerminated Termination = iota
+    Checkmate
+    Stalemate
+    InsufficientMaterial
+    SeventyFiveMoveRule
+    FivefoldRepetition
+    FiftyMoveRule       // Claimed with ClaimDraw
+    ThreefoldRepetition // Claimed with ClaimDraw
+    Resignation
+    Timeout
+    Agreement
+)
+
+var terminationNames = [...]string{
+    NotTerminated:        "none",
+    Checkmate:            "checkmate",
+    Stalemate:            "stalemate",
+    InsufficientMaterial: "insufficient material",
+    SeventyFiveMoveRule:  "75-move rule",
+    FivefoldRepetition:   "fivefold repetition",
+    FiftyMoveRule:        "50-move rule",
+    ThreefoldRepetition:  "threefold repetition",
+    Resignation:          "resignation",
+    Timeout:              "timeout",
+    Agreement:            "agreement",
+}
+
+func (t Termination) String() string {
+    if t < 0 || int(t) >= len(terminationNames) {
+        return "unknown"
+    }
+    return terminationNames[t]
+}
+
+// Outcome is the result of a game together with the reason it ended.
+type Outcome struct {
+    Result      Result
+    Termination Termination
+}
+
+// Outcome reports whether the position ends the game by the rules alone:
+// checkmate, stalemate, insufficient material, the 75-move rule or fivefold
+// repetition. Draws that a player has to claim, by the 50-move rule or
+// threefold repetition, do not end the game here. A game in progress has
+// NoResult and NotTerminated.
+func (b *Board) Outcome() Outcome {
+    isBlack := b.CurrentTurn == Black
+    if !b.hasLegalMove(isBlack) {
+        if !b.IsCheck(isBlack) {
+            return Outcome{DrawResult, Stalemate}
+        }
+        if isBlack {
+            return Outcome{WhiteWon, Checkmate}
+        }
+        return Outcome{BlackWon, Checkmate}
+    }
+
+    switch {
+    case b.IsInsufficientMaterial():
+        return Outcome{DrawResult, InsufficientMaterial}
+    case b.IsDrawBySeventyFiveMoveRule():
+        return Outcome{DrawResult, SeventyFiveMoveRule}
+    case b.IsDrawByFivefoldRepetition():
+        return Outcome{DrawResult, FivefoldRepetition}
+    }
+    return Outcome{NoResult, NotTerminated}
+}
+
+// GameStatus tells whether a game is still going on and, if not, who won.
+type GameStatus int
+
+const (
+    Ongoing GameStatus = iota
+    WhiteWins
+    BlackWins
+    Draw
+)
+
+var gameStatusNames = [...]string{
+    Ongoing:   "ongoing",
+    WhiteWins: "white wins",
+    BlackWins: "black wins",
+    Draw:      "draw",
+}
+
+func (s GameStatus) String() string {
+    if s < 0 || int(s) >= len(gameStatusNames) {
+        return "unknown"
+    }
+    return gameStatusNames[s]
+}
+
+// Result returns the PGN result of the status.
+func (s GameStatus) Result() Result {
+    switch s {
+    case WhiteWins:
+        return WhiteWon
+    case BlackWins:
+        return BlackWon
+    case Draw:
+        return DrawResult
+    }
+    return NoResult
+}
+
+// Status returns the state of the game and why it ended. A resignation,
+// timeout, draw agreement or claimed draw takes precedence; otherwise the
+// position is judged as by Outcome.
+func (b *Board) Status() (GameStatus, Termination) {
+    outcome := b.ending
+    if outcome.Termination == NotTerminated {
+        outcome = b.Outcome()
+    }
+
+    switch outcome.Result {
+    case WhiteWon:
+        return WhiteWins, outcome.Termination
+    case BlackWon:
+        return BlackWins, outcome.Termination
+    case DrawResult:
+        return Draw, outcome.Termination
+    }
+    return Ongoing, NotTerminated
+}
+
+// IsGameOver reports whether Status is anything other than Ongoing.
+func (b *Board) IsGameOver() bool {
+    status, _ := b.Status()
+    return status != Ongoing
+}
+
+// Resign ends the game with a win for the opponent of color.
+func (b *Board) Resign(color Color) error {
+    return b.end(winnerResult(color.Opponent()), Resignation)
+}
+
+// Timeout ends the game because color ran out of time, with a win for the
+// opponent.
+func (b *Board) Timeout(color Color) error {
+    return b.end(winnerResult(color.Opponent()), Timeout)
+}
+
+// AgreeDraw ends the game in a draw agreed by both players.
+func (b *Board) AgreeDraw() error {
+    return b.end(DrawResult, Agreement)
+}
+
+// ClaimDraw ends the game in a draw if the 50-move rule or threefold
+// repetition allows a claim, and returns ErrNoDrawToClaim otherwise.
+func (b *Board) ClaimDraw() error {
+    switch {
+    case b.IsDrawByThreefoldRepetition():
+        return b.end(DrawResult, ThreefoldRepetition)
+    case b.IsDrawByFiftyMoveRule():
+        return b.end(DrawResult, FiftyMoveRule)
+    }
+    if b.IsGameOver() {
+        return ErrGameOver
+    }
+    return ErrNoDrawToClaim
+}
+
+// Ending returns the result recorded by Resign, Timeout, AgreeDraw or
+// ClaimDraw, or an outcome that is NotTerminated if there is none.
+func (b *Board) Ending() Outcome {
+    return b.ending
+}
+
+// end records a result that the position alone does not decide. UnmakeMove
+// restores the ending from before the move it takes back, so taking back the
+// last move before the game ended clears it again.
+func (b *Board) end(result Result, termination Termination) error {
+    if b.IsGameOver() {
+        return ErrGameOver
+    }
+    b.ending = Outcome{result, termination}
+    return nil
+}
+
+func winnerResult(color Color) Result {
+    if color == White {
+        return WhiteWon
+    }
+    return BlackWon
+}
@@ -0,0 +1,55 @@
+package board
+
+// Perft counts the leaf nodes of the legal move tree to the given depth. The
+// counts can be compared with published values to validate move generation.
+// The search runs on the bitboard representation of the position.
+func (b *Board) Perft(depth int) uint64 {
+    return b.Bitboards().Perft(depth)
+}
+
+// Divide runs Perft below each legal move and returns the node count per move.
+// It is used to find which move a wrong perft total comes from.
+func (b *Board) Divide(depth int) map[Move]uint64 {
+    counts := make(map[Move]uint64)
+    if depth <= 0 {
+        return counts
+    }
+
+    p := b.Bitboards()
+    var moves [256]bbMove
+    for _, m := range p.generate(moves[:0]) {
+        next := *p
+        next.makeMove(m)
+        if next.inCheck(p.Turn) {
+            continue
+        }
+        move := Move{Start: m.from.Position(), End: m.to.Position(), Promotion: m.promotion}
+        move.Piece = b.GetPieceAt(move.Start)
+        if m.castling && !b.Chess960 {
+            move.End = castlingFor(p.rooks, move.Start, p.Turn == Black, m.to > m.from).kingTo
+        }
+        counts[move] = next.Perft(depth - 1)
+    }
+    return counts
+}
+
+// perftMailbox is Perft on the 8x8 board using LegalMoves and MakeMove. It
+// cross-checks the bitboard generator and serves as the benchmark baseline.
+func (b *Board) perftMailbox(depth int) uint64 {
+    if depth <= 0 {
+        return 1
+    }
+
+    moves := b.LegalMoves()
+    if depth == 1 {
+        return uint64(len(moves))
+    }
+
+    var nodes uint64
+    for _, move := range moves {
+        b.MakeMove(move)
+        nodes += b.perftMailbox(depth - 1)
+        b.UnmakeMove()
+    }
+    return nodes
+}
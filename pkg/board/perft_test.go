@@ -0,0 +1,207 @@
+package board
+
+import (
+    "testing"
+)
+
+// perftPositions are the standard perft test positions with known node
+// counts, indexed by depth starting at 1.
+var perftPositions = []struct {
+    name  string
+    fen   string
+    nodes []uint64
+}{
+    {"start", StartFEN, []uint64{20, 400, 8902, 197281}},
+    {"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []uint64{48, 2039, 97862, 4085603}},
+    {"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []uint64{14, 191, 2812, 43238, 674624}},
+    {"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []uint64{6, 264, 9467}},
+    {"position 4 mirrored", "r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1", []uint64{6, 264, 9467}},
+    {"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []uint64{44, 1486, 62379}},
+    {"position 6", "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", []uint64{46, 2079, 89890}},
+}
+
+func TestPerft(t *testing.T) {
+    for _, pos := range perftPositions {
+        b, err := FromFEN(pos.fen)
+        if err != nil {
+            t.Fatalf("%s: %v", pos.name, err)
+        }
+        for i, want := range pos.nodes {
+            depth := i + 1
+            if testing.Short() && want > 100000 {
+                break
+            }
+            if got := b.Perft(depth); got != want {
+                t.Errorf("%s: perft(%d) = %d, want %d", pos.name, depth, got, want)
+            }
+        }
+        if b.FEN() != pos.fen {
+            t.Errorf("%s: expected perft to leave the position unchanged, got %q", pos.name, b.FEN())
+        }
+    }
+}
+
+// chess960Positions are Chess960 perft positions, with castling rights in
+// Shredder-FEN.
+var chess960Positions = []struct {
+    name  string
+    fen   string
+    nodes []uint64
+}{
+    {"chess960 1", "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9", []uint64{21, 528, 12189, 326672}},
+    {"chess960 2", "2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9", []uint64{21, 807, 18002}},
+    {"chess960 3", "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9", []uint64{20, 479, 10471}},
+    // The b1 rook shields c1 from the a1 rook only until it castles
+    {"chess960 castling into check", "7k/8/8/8/8/8/8/rR1K4 w B - 0 1", []uint64{7, 70, 994, 14431}},
+}
+
+func TestPerftChess960(t *testing.T) {
+    for _, pos := range chess960Positions {
+        b, err := FromFEN(pos.fen)
+        if err != nil {
+            t.Fatalf("%s: %v", pos.name, err)
+        }
+        if !b.Chess960 {
+            t.Errorf("%s: expected the FEN to select Chess960", pos.name)
+        }
+        fen := b.FEN()
+        for i, want := range pos.nodes {
+            depth := i + 1
+            if testing.Short() && want > 100000 {
+                break
+            }
+            if got := b.Perft(depth); got != want {
+                t.Errorf("%s: perft(%d) = %d, want %d", pos.name, depth, got, want)
+            }
+        }
+        if b.FEN() != fen {
+            t.Errorf("%s: expected perft to leave the position unchanged, got %q", pos.name, b.FEN())
+        }
+    }
+}
+
+func TestDivideMatchesLegalMoves(t *testing.T) {
+    for _, fen := range []string{perftPositions[1].fen, chess960Positions[0].fen, chess960Positions[3].fen} {
+        b, err := FromFEN(fen)
+        if err != nil {
+            t.Fatal(err)
+        }
+        counts := b.Divide(1)
+        legal := b.LegalMoves()
+        if len(counts) != len(legal) {
+            t.Errorf("%s: divide has %d moves, want %d", fen, len(counts), len(legal))
+        }
+        for _, m := range legal {
+            if _, ok := counts[m]; !ok {
+                t.Errorf("%s: divide is missing %s", fen, m.UCI())
+            }
+        }
+    }
+}
+
+func TestDivide(t *testing.T) {
+    b := NewBoard()
+    counts := b.Divide(3)
+    if len(counts) != 20 {
+        t.Fatalf("Expected 20 root moves, got %d", len(counts))
+    }
+
+    var total uint64
+    for _, nodes := range counts {
+        total += nodes
+    }
+    if total != 8902 {
+        t.Errorf("Expected divide to sum to 8902, got %d", total)
+    }
+
+    e2e4 := Move{Start: Position{1, 4}, End: Position{3, 4}, Piece: WhitePawn}
+    if counts[e2e4] != 600 {
+        t.Errorf("Expected 600 nodes after e2e4, got %d", counts[e2e4])
+    }
+}
+
+// TestPerftMailbox runs the published positions through the move generator
+// behind LegalMoves, which Perft and Divide do not use.
+func TestPerftMailbox(t *testing.T) {
+    positions := append(perftPositions[:len(perftPositions):len(perftPositions)], chess960Positions...)
+    for _, pos := range positions {
+        b, err := FromFEN(pos.fen)
+        if err != nil {
+            t.Fatalf("%s: %v", pos.name, err)
+        }
+        for i, want := range pos.nodes[:min(len(pos.nodes), 4)] {
+            depth := i + 1
+            if testing.Short() && want > 100000 {
+                break
+            }
+            if got := b.perftMailbox(depth); got != want {
+                t.Errorf("%s: mailbox perft(%d) = %d, want %d", pos.name, depth, got, want)
+            }
+        }
+    }
+}
+
+func TestBitboardsRoundTrip(t *testing.T) {
+    for _, pos := range perftPositions {
+        b, err := FromFEN(pos.fen)
+        if err != nil {
+            t.Fatalf("%s: %v", pos.name, err)
+        }
+        p := b.Bitboards()
+        if p.Occupied.Count() != countPieces(b) {
+            t.Errorf("%s: expected one bit per piece, got %d", pos.name, p.Occupied.Count())
+        }
+        if got := p.Board().FEN(); got != pos.fen {
+            t.Errorf("%s: expected round trip through bitboards, got %q", pos.name, got)
+        }
+    }
+}
+
+func TestBitboardAttacks(t *testing.T) {
+    b, err := FromFEN("4k3/8/8/3p4/8/8/8/R2K4 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    p := b.Bitboards()
+
+    // The rook on a1 is blocked by the king on d1
+    got := rookAttacks(Position{0, 0}.Square(), p.Occupied)
+    if got.Count() != 10 || !got.Has(Position{0, 3}) || got.Has(Position{0, 4}) {
+        t.Errorf("Expected a1 rook to see a2-a8 and b1-d1, got %v", got.Positions())
+    }
+    if got := bishopAttacks(Position{0, 0}.Square(), p.Occupied); !got.Has(Position{7, 7}) || got.Count() != 7 {
+        t.Errorf("Expected a1 bishop to see the long diagonal, got %v", got.Positions())
+    }
+    if knightAttacks[Position{0, 0}.Square()].Count() != 2 || knightAttacks[Position{3, 3}.Square()].Count() != 8 {
+        t.Error("Expected 2 knight attacks from a1 and 8 from d4")
+    }
+    if p.inCheck(White) != b.IsCheck(false) {
+        t.Error("Expected bitboard check detection to match the board")
+    }
+}
+
+func countPieces(b *Board) int {
+    n := 0
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            if b.Squares[row][col] != 0 {
+                n++
+            }
+        }
+    }
+    return n
+}
+
+func BenchmarkPerftMailbox(b *testing.B) {
+    board, _ := FromFEN(perftPositions[1].fen)
+    for i := 0; i < b.N; i++ {
+        board.perftMailbox(3)
+    }
+}
+
+func BenchmarkPerftBitboards(b *testing.B) {
+    board, _ := FromFEN(perftPositions[1].fen)
+    for i := 0; i < b.N; i++ {
+        board.Perft(3)
+    }
+}
@@ -1,36 +1,242 @@
 package board
 
+import (
+    "encoding/json"
+    "fmt"
+)
+
+// PieceType is the kind of a piece, regardless of its color.
+type PieceType int
+
+const (
+    NoPieceType PieceType = iota
+    Rook
+    Knight
+    Bishop
+    Queen
+    King
+    Pawn
+)
+
+// Color is the side a piece belongs to.
+type Color int
+
+const (
+    White Color = 8
+    Black Color = 16
+)
+
+// Piece is a piece type combined with its color. The zero value, NoPiece,
+// marks an empty square.
+type Piece int
+
+const NoPiece Piece = 0
+
 const (
-    Rook   = 1
-    Knight = 2
-    Bishop = 3
-    Queen  = 4
-    King   = 5
-    Pawn   = 6
-
-    White = 8
-    Black = 16
+    WhiteRook   = Piece(Rook) | Piece(White)
+    WhiteKnight = Piece(Knight) | Piece(White)
+    WhiteBishop = Piece(Bishop) | Piece(White)
+    WhiteQueen  = Piece(Queen) | Piece(White)
+    WhiteKing   = Piece(King) | Piece(White)
+    WhitePawn   = Piece(Pawn) | Piece(White)
+
+    BlackRook   = Piece(Rook) | Piece(Black)
+    BlackKnight = Piece(Knight) | Piece(Black)
+    BlackBishop = Piece(Bishop) | Piece(Black)
+    BlackQueen  = Piece(Queen) | Piece(Black)
+    BlackKing   = Piece(King) | Piece(Black)
+    BlackPawn   = Piece(Pawn) | Piece(Black)
 )
 
-func (b *Board) GetPieceAt(pos Position) int {
+// typeMask selects the piece type bits of a Piece.
+const typeMask = 0b111
+
+// NewPiece returns the piece of type t and color c.
+func NewPiece(t PieceType, c Color) Piece {
+    return Piece(t) | Piece(c)
+}
+
+// Type returns the piece's type, or NoPieceType for NoPiece.
+func (p Piece) Type() PieceType {
+    return PieceType(p & typeMask)
+}
+
+// Color returns the piece's color. It is only meaningful for a real piece.
+func (p Piece) Color() Color {
+    return Color(p &^ typeMask)
+}
+
+// String returns the FEN letter of the piece: upper case for White, lower
+// case for Black, and "-" for NoPiece.
+func (p Piece) String() string {
+    if p == NoPiece {
+        return "-"
+    }
+    return string(pieceChar(p))
+}
+
+// MarshalJSON encodes a piece as its FEN letter, or null for NoPiece.
+func (p Piece) MarshalJSON() ([]byte, error) {
+    if p == NoPiece {
+        return []byte("null"), nil
+    }
+    return json.Marshal(p.String())
+}
+
+// UnmarshalJSON decodes a FEN letter or null.
+func (p *Piece) UnmarshalJSON(data []byte) error {
+    if string(data) == "null" {
+        *p = NoPiece
+        return nil
+    }
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return err
+    }
+    if len(s) != 1 || pieceChars[s[0]] == NoPiece {
+        return fmt.Errorf("board: invalid piece %q", s)
+    }
+    *p = pieceChars[s[0]]
+    return nil
+}
+
+var pieceTypeNames = [...]string{"", "rook", "knight", "bishop", "queen", "king", "pawn"}
+
+// String returns the lower-case name of the piece type, such as "knight".
+func (t PieceType) String() string {
+    if t <= NoPieceType || int(t) >= len(pieceTypeNames) {
+        return "none"
+    }
+    return pieceTypeNames[t]
+}
+
+// MarshalJSON encodes a piece type by name.
+func (t PieceType) MarshalJSON() ([]byte, error) {
+    return json.Marshal(t.String())
+}
+
+// UnmarshalJSON decodes a piece type name.
+func (t *PieceType) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return err
+    }
+    for i, name := range pieceTypeNames {
+        if name == s && i > 0 {
+            *t = PieceType(i)
+            return nil
+        }
+    }
+    return fmt.Errorf("board: invalid piece type %q", s)
+}
+
+// Opponent returns the other color.
+func (c Color) Opponent() Color {
+    if c == White {
+        return Black
+    }
+    return White
+}
+
+// String returns "white" or "black".
+func (c Color) String() string {
+    switch c {
+    case White:
+        return "white"
+    case Black:
+        return "black"
+    }
+    return "none"
+}
+
+// MarshalJSON encodes a color as "white" or "black".
+func (c Color) MarshalJSON() ([]byte, error) {
+    return json.Marshal(c.String())
+}
+
+// UnmarshalJSON decodes "white" or "black".
+func (c *Color) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return err
+    }
+    switch s {
+    case "white":
+        *c = White
+    case "black":
+        *c = Black
+    default:
+        return fmt.Errorf("board: invalid color %q", s)
+    }
+    return nil
+}
+
+// isPromotionPiece reports whether a pawn may promote to the piece type.
+func isPromotionPiece(pieceType PieceType) bool {
+    for _, p := range promotionPieces {
+        if p == pieceType {
+            return true
+        }
+    }
+    return false
+}
+
+func (b *Board) GetPieceAt(pos Position) Piece {
     return b.Squares[pos.Row][pos.Col]
 }
 
 func (b *Board) IsEmpty(pos Position) bool {
-    return b.GetPieceAt(pos) == 0
+    return b.GetPieceAt(pos) == NoPiece
 }
 
+// SetPieceAt puts piece on pos, or empties the square for NoPiece. It updates
+// the board's bitboards and hashes as it goes, where a direct write to
+// Squares makes the next query rebuild them.
+func (b *Board) SetPieceAt(pos Position, piece Piece) {
+    b.sync()
+    bit := bitAt(pos)
+    if old := b.Squares[pos.Row][pos.Col]; old != NoPiece {
+        b.bitboards.toggle(old, bit)
+        b.toggleHash(old, pos)
+    }
+    if piece != NoPiece {
+        b.bitboards.toggle(piece, bit)
+        b.toggleHash(piece, pos)
+    }
+    b.Squares[pos.Row][pos.Col] = piece
+    b.synced[pos.Row][pos.Col] = piece
+}
+
+// KingPosition returns the square of the king of color, or NoPosition if it
+// has none.
+func (b *Board) KingPosition(color Color) Position {
+    return b.findKing(color == Black)
+}
+
+// findKing returns the square of the king of the given color, or NoPosition
+// if it has none.
 func (b *Board) findKing(isBlack bool) Position {
-    king := King | White
-    if isBlack {
-        king = King | Black
-    }
-    for r := 0; r < 8; r++ {
-        for c := 0; c < 8; c++ {
-            if b.Squares[r][c] == king {
-                return Position{r, c}
-            }
-        }
+    kings := b.pieceBitboards().Pieces[colorIndex(colorOf(isBlack))][King]
+    if kings == 0 {
+        return NoPosition
+    }
+    return kings.lsb().Position()
+}
+
+// pieceChars maps FEN piece letters to pieces.
+var pieceChars = map[byte]Piece{
+    'R': WhiteRook, 'N': WhiteKnight, 'B': WhiteBishop,
+    'Q': WhiteQueen, 'K': WhiteKing, 'P': WhitePawn,
+    'r': BlackRook, 'n': BlackKnight, 'b': BlackBishop,
+    'q': BlackQueen, 'k': BlackKing, 'p': BlackPawn,
+}
+
+// pieceChar returns the FEN letter of a piece: upper case for White, lower
+// case for Black.
+func pieceChar(piece Piece) byte {
+    c := " RNBQKP"[piece.Type()]
+    if piece.Color() == Black {
+        c += 'a' - 'A'
     }
-    return Position{-1, -1} // Error case if king not found
-}
\ No newline at end of file
+    return c
+}
@@ -0,0 +1,169 @@
+package board
+
+// Pin is an absolute pin: the piece on Pinned shields its king from the
+// enemy slider on Pinner and may not leave the line between them.
+type Pin struct {
+    Pinned Position
+    Pinner Position
+    Ray    Bitboard // The squares the pinned piece may still move to, up to and including Pinner
+}
+
+// Pinned returns the absolute pins on the pieces of color.
+func (b *Board) Pinned(color Color) []Pin {
+    s := b.kingSafety(color)
+    var pins []Pin
+    for set := s.pinned; set != 0; {
+        sq := set.popLSB()
+        pinner := s.pins[sq] & s.p.Colors[1-s.us]
+        pins = append(pins, Pin{Pinned: sq.Position(), Pinner: pinner.lsb().Position(), Ray: s.pins[sq]})
+    }
+    return pins
+}
+
+// Checkers returns the pieces giving check to the side to move.
+func (b *Board) Checkers() []Position {
+    return b.kingSafety(b.CurrentTurn).checkers.Positions()
+}
+
+// CheckEvasions returns the legal moves of the side to move when it is in
+// check: king moves, captures of the checking piece and interpositions. It
+// returns nil when the side to move is not in check.
+func (b *Board) CheckEvasions() []Move {
+    s := b.kingSafety(b.CurrentTurn)
+    if s.checkers == 0 {
+        return nil
+    }
+    p, them := s.p, 1-s.us
+    king := s.king.Position()
+    piece := b.GetPieceAt(king)
+
+    // The king may step to any square not attacked once it has left its own,
+    // which a slider giving check along the line could otherwise reach
+    var moves []Move
+    occupied := p.Occupied &^ (Bitboard(1) << uint(s.king))
+    for targets := kingAttacks[s.king] &^ p.Colors[s.us]; targets != 0; {
+        to := targets.popLSB()
+        if p.attackers(to, them, occupied) == 0 {
+            moves = append(moves, Move{Start: king, End: to.Position(), Piece: piece})
+        }
+    }
+    if s.checkers.Count() > 1 {
+        return moves // Only the king can answer a double check
+    }
+
+    // Otherwise capture the checker or step between it and the king. A pinned
+    // piece cannot do either: its ray and the line of the check meet only at
+    // the king.
+    checker := s.checkers.lsb()
+    block := between(s.king, checker) | s.checkers
+    var buf [32]Move
+    for pieces := p.Colors[s.us] &^ p.Pieces[s.us][King] &^ s.pinned; pieces != 0; {
+        from := pieces.popLSB().Position()
+        mover := b.GetPieceAt(from)
+        if mover.Type() != Pawn {
+            for targets := p.Attacks(from.Square()) & block; targets != 0; {
+                moves = append(moves, Move{Start: from, End: targets.popLSB().Position(), Piece: mover})
+            }
+            continue
+        }
+        for _, move := range b.appendPawnMoves(buf[:0], from, mover) {
+            if move.End == b.EnPassant && move.Start.Col != move.End.Col {
+                // Only the checking pawn can be taken en passant, and only
+                // if that does not expose the king along the rank
+                if checker == (Position{move.Start.Row, move.End.Col}).Square() && s.allows(b, move) {
+                    moves = append(moves, move)
+                }
+            } else if block.Has(move.End) {
+                moves = append(moves, move)
+            }
+        }
+    }
+    return moves
+}
+
+// kingSafety holds the checks and pins against one side's king, so that
+// pseudo-legal moves can be tested without playing them.
+type kingSafety struct {
+    p        *Bitboards
+    us       int
+    king     Square // NoSquare if the side has no king
+    checkers Bitboard
+    pinned   Bitboard
+    pins     [64]Bitboard // The ray each pinned piece is confined to
+}
+
+func (b *Board) kingSafety(color Color) *kingSafety {
+    p := b.pieceBitboards()
+    s := &kingSafety{p: p, us: colorIndex(color), king: NoSquare}
+    kings := p.Pieces[s.us][King]
+    if kings == 0 {
+        return s
+    }
+    s.king = kings.lsb()
+    them := 1 - s.us
+    s.checkers = p.attackers(s.king, them, p.Occupied)
+
+    // Sliders that would attack the king if only their own side's pieces
+    // stood in the way
+    enemies := p.Pieces[them]
+    snipers := rookAttacks(s.king, p.Colors[them])&(enemies[Rook]|enemies[Queen]) |
+        bishopAttacks(s.king, p.Colors[them])&(enemies[Bishop]|enemies[Queen])
+    for snipers != 0 {
+        sniper := snipers.popLSB()
+        ray := between(s.king, sniper)
+        if blockers := ray & p.Occupied; blockers.Count() == 1 && blockers&p.Colors[s.us] != 0 {
+            s.pinned |= blockers
+            s.pins[blockers.lsb()] = ray | Bitboard(1)<<uint(sniper)
+        }
+    }
+    return s
+}
+
+// allows reports whether a pseudo-legal move of this side leaves its king
+// safe.
+func (s *kingSafety) allows(b *Board, move Move) bool {
+    if s.king == NoSquare {
+        return true
+    }
+    if _, ok := b.castlingOf(move); ok {
+        return true // Castling is only generated when it is legal
+    }
+
+    from, to := move.Start.Square(), move.End.Square()
+    if from == s.king {
+        // Lift the king so it cannot block a slider attacking its new square
+        occupied := s.p.Occupied &^ (Bitboard(1) << uint(from))
+        return s.p.attackers(to, 1-s.us, occupied) == 0
+    }
+    if move.Piece.Type() == Pawn && move.End == b.EnPassant && move.Start.Col != move.End.Col {
+        // Removing two pawns from one rank can expose the king sideways
+        tempBoard := *b
+        tempBoard.applyMove(move)
+        return !tempBoard.IsCheck(s.us == 1)
+    }
+
+    if s.checkers.Count() > 1 {
+        return false // Only the king can answer a double check
+    }
+    target := Bitboard(1) << uint(to)
+    if s.pinned&(Bitboard(1)<<uint(from)) != 0 && s.pins[from]&target == 0 {
+        return false
+    }
+    if s.checkers != 0 {
+        checker := s.checkers.lsb()
+        return (between(s.king, checker)|s.checkers)&target != 0
+    }
+    return true
+}
+
+// between returns the squares strictly between a and b if they share a rank,
+// file or diagonal, and an empty set otherwise.
+func between(a, b Square) Bitboard {
+    target := Bitboard(1) << uint(b)
+    for dir := range rays {
+        if rays[dir][a]&target != 0 {
+            return rays[dir][a] &^ rays[dir][b] &^ target
+        }
+    }
+    return 0
+}
@@ -0,0 +1,82 @@
+package board
+
+import "strings"
+
+// RenderOptions controls how Render draws the board.
+type RenderOptions struct {
+    Unicode     bool  // Chess symbols such as ♘ instead of FEN letters
+    Orientation Color // The side shown at the bottom; White if unset
+    Coordinates bool  // Label the ranks and files
+    LastMove    bool  // Bracket the start and end squares of the last move
+    Check       bool  // Mark the king of the side to move when it is in check
+}
+
+var unicodePieces = map[Piece]string{
+    WhiteKing: "♔", WhiteQueen: "♕", WhiteRook: "♖", WhiteBishop: "♗", WhiteKnight: "♘", WhitePawn: "♙",
+    BlackKing: "♚", BlackQueen: "♛", BlackRook: "♜", BlackBishop: "♝", BlackKnight: "♞", BlackPawn: "♟",
+}
+
+// Render draws the board as text, one rank per line. Every square takes
+// three characters: the piece between two spaces, or "." for an empty
+// square. A square of the last move is drawn as "[N]" and a king in check as
+// "(K)".
+func (b *Board) Render(opts RenderOptions) string {
+    lastMove := [2]Position{NoPosition, NoPosition}
+    if opts.LastMove && b.LastMove.Start != b.LastMove.End {
+        lastMove = [2]Position{b.LastMove.Start, b.LastMove.End}
+    }
+    checked := NoPosition
+    if opts.Check && b.IsCheck(b.CurrentTurn == Black) {
+        checked = b.findKing(b.CurrentTurn == Black)
+    }
+
+    // Rows and columns in drawing order, top left first
+    rows, cols := [8]int{7, 6, 5, 4, 3, 2, 1, 0}, [8]int{0, 1, 2, 3, 4, 5, 6, 7}
+    if opts.Orientation == Black {
+        rows, cols = cols, rows
+    }
+
+    var sb strings.Builder
+    for _, row := range rows {
+        var line strings.Builder
+        if opts.Coordinates {
+            line.WriteByte(byte('1' + row))
+            line.WriteByte(' ')
+        }
+        for _, col := range cols {
+            pos := Position{row, col}
+            left, right := " ", " "
+            switch pos {
+            case checked:
+                left, right = "(", ")"
+            case lastMove[0], lastMove[1]:
+                left, right = "[", "]"
+            }
+            line.WriteString(left)
+            line.WriteString(renderPiece(b.GetPieceAt(pos), opts.Unicode))
+            line.WriteString(right)
+        }
+        sb.WriteString(strings.TrimRight(line.String(), " "))
+        sb.WriteByte('\n')
+    }
+
+    if opts.Coordinates {
+        sb.WriteByte(' ')
+        for _, col := range cols {
+            sb.WriteString("  ")
+            sb.WriteByte(byte('a' + col))
+        }
+        sb.WriteByte('\n')
+    }
+    return sb.String()
+}
+
+func renderPiece(piece Piece, unicode bool) string {
+    switch {
+    case piece == NoPiece:
+        return "."
+    case unicode:
+        return unicodePieces[piece]
+    }
+    return piece.String()
+}
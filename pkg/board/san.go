@@ -0,0 +1,195 @@
+package board
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+)
+
+var (
+    ErrInvalidSAN   = errors.New("board: malformed SAN move")
+    ErrIllegalSAN   = errors.New("board: no legal move matches SAN")
+    ErrAmbiguousSAN = errors.New("board: SAN move matches more than one legal move")
+)
+
+// sanPieces maps SAN piece letters to piece types.
+var sanPieces = map[byte]PieceType{
+    'N': Knight,
+    'B': Bishop,
+    'R': Rook,
+    'Q': Queen,
+    'K': King,
+}
+
+// SAN returns move in Standard Algebraic Notation, such as "Nf3", "exd5",
+// "O-O-O" or "e8=Q+". The move should be legal in the current position.
+func (b *Board) SAN(move Move) string {
+    piece := b.GetPieceAt(move.Start)
+    move.Piece = piece
+    pieceType := piece.Type()
+
+    var sb strings.Builder
+    if c, ok := b.castlingOf(move); ok {
+        if c.rookFrom.Col > c.kingFrom.Col {
+            sb.WriteString("O-O")
+        } else {
+            sb.WriteString("O-O-O")
+        }
+    } else {
+        capture := !b.IsEmpty(move.End) || (pieceType == Pawn && move.Start.Col != move.End.Col)
+        if pieceType == Pawn {
+            if capture {
+                sb.WriteByte(byte('a' + move.Start.Col))
+            }
+        } else {
+            sb.WriteByte(pieceChar(NewPiece(pieceType, White)))
+            sb.WriteString(b.sanDisambiguation(move))
+        }
+        if capture {
+            sb.WriteByte('x')
+        }
+        sb.WriteString(move.End.String())
+        if move.Promotion != NoPieceType {
+            sb.WriteByte('=')
+            sb.WriteByte(pieceChar(NewPiece(move.Promotion, White)))
+        }
+    }
+
+    // Play the move on a copy to find check and checkmate
+    tempBoard := *b
+    tempBoard.history = nil
+    tempBoard.MakeMove(move)
+    isBlack := tempBoard.CurrentTurn == Black
+    if tempBoard.IsCheck(isBlack) {
+        if tempBoard.hasLegalMove(isBlack) {
+            sb.WriteByte('+')
+        } else {
+            sb.WriteByte('#')
+        }
+    }
+    return sb.String()
+}
+
+// sanDisambiguation returns the file, rank or square needed to tell move
+// apart from other legal moves of the same piece type to the same square.
+func (b *Board) sanDisambiguation(move Move) string {
+    sameFile, sameRank, others := false, false, false
+    for _, m := range b.legalMoves(move.Piece.Color() == Black) {
+        if m.End != move.End || m.Start == move.Start || m.Piece != move.Piece {
+            continue
+        }
+        others = true
+        if m.Start.Col == move.Start.Col {
+            sameFile = true
+        }
+        if m.Start.Row == move.Start.Row {
+            sameRank = true
+        }
+    }
+
+    switch {
+    case !others:
+        return ""
+    case !sameFile:
+        return string(rune('a' + move.Start.Col))
+    case !sameRank:
+        return string(rune('1' + move.Start.Row))
+    default:
+        return move.Start.String()
+    }
+}
+
+// ParseSAN finds the legal move described by a move in Standard Algebraic
+// Notation. Check, mate and annotation suffixes are ignored, and castling may
+// be written with letters or zeros.
+func (b *Board) ParseSAN(san string) (Move, error) {
+    s := strings.TrimRight(strings.TrimSpace(san), "+#!?")
+    if s == "" {
+        return Move{}, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+    }
+
+    legal := b.LegalMoves()
+    switch s {
+    case "O-O", "0-0", "O-O-O", "0-0-0":
+        kingside := len(s) == 3
+        for _, m := range legal {
+            if c, ok := b.castlingOf(m); ok && (c.rookFrom.Col > c.kingFrom.Col) == kingside {
+                return m, nil
+            }
+        }
+        return Move{}, fmt.Errorf("%w: %q", ErrIllegalSAN, san)
+    }
+
+    pieceType := Pawn
+    if p, ok := sanPieces[s[0]]; ok {
+        pieceType = p
+        s = s[1:]
+    }
+
+    promotion := NoPieceType
+    if i := strings.IndexByte(s, '='); i >= 0 {
+        if i != len(s)-2 {
+            return Move{}, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+        }
+        p, ok := sanPieces[s[i+1]]
+        if !ok || !isPromotionPiece(p) {
+            return Move{}, fmt.Errorf("%w: invalid promotion piece in %q", ErrInvalidSAN, san)
+        }
+        promotion = p
+        s = s[:i]
+    } else if pieceType == Pawn && len(s) > 2 {
+        if p, ok := sanPieces[s[len(s)-1]]; ok && isPromotionPiece(p) {
+            promotion = p // Promotion written without "=", as in "e8Q"
+            s = s[:len(s)-1]
+        }
+    }
+
+    if len(s) < 2 {
+        return Move{}, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+    }
+    end, err := ParsePosition(s[len(s)-2:])
+    if err != nil {
+        return Move{}, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+    }
+    s = strings.TrimSuffix(s[:len(s)-2], "x")
+
+    // Whatever is left disambiguates the start square
+    fromCol, fromRow := -1, -1
+    for i := 0; i < len(s); i++ {
+        switch c := s[i]; {
+        case c >= 'a' && c <= 'h' && fromCol < 0 && fromRow < 0:
+            fromCol = int(c - 'a')
+        case c >= '1' && c <= '8' && fromRow < 0:
+            fromRow = int(c - '1')
+        default:
+            return Move{}, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+        }
+    }
+    // A pawn capture always names the file it leaves, so "d5" is never cxd5
+    if pieceType == Pawn && fromCol < 0 {
+        fromCol = end.Col
+    }
+
+    var matches []Move
+    for _, m := range legal {
+        if m.Piece.Type() != pieceType || m.End != end || m.Promotion != promotion {
+            continue
+        }
+        if _, ok := b.castlingOf(m); ok {
+            continue
+        }
+        if (fromCol >= 0 && m.Start.Col != fromCol) || (fromRow >= 0 && m.Start.Row != fromRow) {
+            continue
+        }
+        matches = append(matches, m)
+    }
+
+    switch len(matches) {
+    case 0:
+        return Move{}, fmt.Errorf("%w: %q", ErrIllegalSAN, san)
+    case 1:
+        return matches[0], nil
+    default:
+        return Move{}, fmt.Errorf("%w: %q", ErrAmbiguousSAN, san)
+    }
+}
@@ -0,0 +1,96 @@
+package board
+
+// seeValues are the piece values, in centipawns, used by static exchange
+// evaluation, indexed by PieceType.
+var seeValues = [...]int{
+    NoPieceType: 0,
+    Pawn:        100,
+    Knight:      320,
+    Bishop:      330,
+    Rook:        500,
+    Queen:       900,
+    King:        20000,
+}
+
+// seeOrder lists the piece types from least to most valuable, the order in
+// which they join an exchange.
+var seeOrder = [...]PieceType{Pawn, Knight, Bishop, Rook, Queen, King}
+
+// SEE returns the material balance, in centipawns, for the side making move
+// if both sides then keep recapturing on its destination square with their
+// least valuable attacker, and either side may stop when continuing would
+// lose material. Sliding pieces lined up behind an attacker join once it
+// has captured. Quiet moves score 0 unless the piece can be taken, and
+// castling always scores 0. Only the promotion of move itself is counted.
+func (b *Board) SEE(move Move) int {
+    piece := b.GetPieceAt(move.Start)
+    if piece == NoPiece {
+        return 0
+    }
+    if _, ok := b.castlingOf(move); ok {
+        return 0
+    }
+
+    p := b.pieceBitboards()
+    to := move.End.Square()
+    occupied := p.Occupied &^ bitAt(move.Start)
+
+    var gain [32]int
+    gain[0] = seeValues[b.GetPieceAt(move.End).Type()]
+    if piece.Type() == Pawn && move.End == b.EnPassant && move.Start.Col != move.End.Col {
+        gain[0] = seeValues[Pawn]
+        occupied &^= bitAt(Position{move.Start.Row, move.End.Col})
+    }
+    onSquare := piece.Type()
+    if move.Promotion != NoPieceType {
+        gain[0] += seeValues[move.Promotion] - seeValues[Pawn]
+        onSquare = move.Promotion
+    }
+
+    side := colorIndex(piece.Color())
+    depth := 0
+    for depth < len(gain)-1 {
+        side = 1 - side
+        attackers := p.AttackersTo(to, occupied)
+        sq, attacker := p.leastValuableAttacker(attackers & p.Colors[side])
+        if sq == NoSquare {
+            break
+        }
+        // The king may only recapture on an undefended square
+        if attacker == King && attackers&p.Colors[1-side] != 0 {
+            break
+        }
+
+        depth++
+        gain[depth] = seeValues[onSquare] - gain[depth-1]
+        occupied &^= Bitboard(1) << uint(sq)
+        onSquare = attacker
+    }
+
+    // Each side only recaptures when that is better than stopping
+    for ; depth > 0; depth-- {
+        gain[depth-1] = -max(-gain[depth-1], gain[depth])
+    }
+    return gain[0]
+}
+
+// SEEGreaterOrEqual reports whether the static exchange evaluation of move
+// is at least threshold, such as 0 to tell winning and even captures from
+// losing ones.
+func (b *Board) SEEGreaterOrEqual(move Move, threshold int) bool {
+    return b.SEE(move) >= threshold
+}
+
+// leastValuableAttacker returns the square and type of the least valuable
+// piece in attackers, or NoSquare if the set is empty.
+func (p *Bitboards) leastValuableAttacker(attackers Bitboard) (Square, PieceType) {
+    if attackers == 0 {
+        return NoSquare, NoPieceType
+    }
+    for _, pieceType := range seeOrder {
+        if set := attackers & (p.Pieces[0][pieceType] | p.Pieces[1][pieceType]); set != 0 {
+            return set.lsb(), pieceType
+        }
+    }
+    return NoSquare, NoPieceType
+}
@@ -1,146 +1,135 @@
 package board
 
+// IsCheck reports whether the king of the given color is attacked
 func (b *Board) IsCheck(isBlack bool) bool {
-    kingPos := b.findKing(isBlack)
-    
-    // Check for rook/queen in the same row/column
-    for r := 0; r < 8; r++ {
-        if r != kingPos.Row {
-            piece := b.GetPieceAt(Position{r, kingPos.Col})
-            if piece != 0 && b.isEnemyPiece(piece, isBlack) && (piece&^Black == Rook || piece&^Black == Queen) {
-                return true // Rook or Queen can attack the king
-            }
-        }
-    }
-    for c := 0; c < 8; c++ {
-        if c != kingPos.Col {
-            piece := b.GetPieceAt(Position{kingPos.Row, c})
-            if piece != 0 && b.isEnemyPiece(piece, isBlack) && (piece&^Black == Rook || piece&^Black == Queen) {
-                return true // Rook or Queen can attack the king
-            }
-        }
-    }
-
-    // Additional check for other pieces (knights, bishops, pawns) can be added here
-    return false
+    return b.pieceBitboards().inCheck(colorOf(isBlack))
 }
 
-
-
 // IsCheckmate checks if the current player is in checkmate
 func (b *Board) IsCheckmate(isBlack bool) bool {
-    // First, check if the player is in check
-    if !b.IsCheck(isBlack) {
-        return false // The king is not in check
-    }
-
-    // Go through each piece on the board to see if there is any legal move
-    for row := 0; row < 8; row++ {
-        for col := 0; col < 8; col++ {
-            piece := b.Squares[row][col]
-            
-            // Ensure the piece belongs to the current player
-            if piece != 0 && (piece&Black != 0) == isBlack {
-                possibleMoves := b.GenerateMoves(Position{Row: row, Col: col})
-
-                // For each possible move, check if it gets the player out of check
-                for _, move := range possibleMoves {
-                    // Create a copy of the board to simulate the move
-                    tempBoard := *b
-
-                    // Simulate the move on the temporary board
-                    tempBoard.MovePiece(Position{Row: row, Col: col}, move)
-
-                    // Check if the player is still in check after the move
-                    if !tempBoard.IsCheck(isBlack) {
-                        return false // Found a move that gets out of check, no checkmate
-                    }
-                }
-            }
-        }
-    }
-
-    // No legal moves were found, so it's checkmate
-    return true
+    return b.IsCheck(isBlack) && !b.hasLegalMove(isBlack)
 }
 
 // IsStalemate checks if the current player is in stalemate
 func (b *Board) IsStalemate(isBlack bool) bool {
-    if b.IsCheck(isBlack) {
-        return false // Stalemate only occurs when the king is NOT in check
-    }
+    return !b.IsCheck(isBlack) && !b.hasLegalMove(isBlack)
+}
 
-    // Check if there are any legal moves
-    for row := 0; row < 8; row++ {
-        for col := 0; col < 8; col++ {
-            piece := b.Squares[row][col]
-            if piece != 0 && (piece&Black != 0) == isBlack {
-                possibleMoves := b.GenerateMoves(Position{Row: row, Col: col})
-                for _, move := range possibleMoves {
-                    // Create a temporary copy of the board and simulate the move
-                    tempBoard := *b
-                    tempBoard.MovePiece(Position{Row: row, Col: col}, move)
-                    if !tempBoard.IsCheck(isBlack) {
-                        return false // There are legal moves, no stalemate
-                    }
-                }
-            }
-        }
+func (b *Board) canCaptureEnPassant(start, end Position, isBlack bool) bool {
+    // The target square lies behind a pawn that has just moved two squares
+    targetRow := 5
+    if isBlack {
+        targetRow = 2
+    }
+    if end != b.EnPassant || end.Row != targetRow {
+        return false
+    }
+    if start.Row+direction(isBlack) != end.Row || abs(start.Col-end.Col) != 1 {
+        return false
     }
+    return b.GetPieceAt(Position{start.Row, end.Col}) == NewPiece(Pawn, colorOf(isBlack).Opponent())
+}
 
-    return true // No legal moves, stalemate
+func (b *Board) canCastleKingside(isBlack bool) bool {
+    return b.canCastle(isBlack, true)
 }
 
-func (b *Board) canCaptureEnPassant(start, end Position, isBlack bool) bool {
-    lastMove := b.GetLastMove()
+func (b *Board) canCastleQueenside(isBlack bool) bool {
+    return b.canCastle(isBlack, false)
+}
 
-    // Check en passant eligibility
-    if (isBlack && start.Row == 3) || (!isBlack && start.Row == 4) {
-        sidePawnPos := Position{Row: start.Row, Col: end.Col}
-        sidePawn := b.GetPieceAt(sidePawnPos)
+// canCastle reports whether the side still has the castling right, its king
+// and rook stand on their squares with nothing else between or on their
+// destinations, and the king is not in check. Attacks on the squares the king
+// crosses are checked by castlingMoves.
+func (b *Board) canCastle(isBlack, kingside bool) bool {
+    right := queensideRight(isBlack)
+    if kingside {
+        right = kingsideRight(isBlack)
+    }
+    king := b.findKing(isBlack)
+    if !b.Castling.Has(right) || king.Row != homeRow(isBlack) {
+        return false
+    }
 
-        if sidePawn != 0 && sidePawn&Pawn != 0 && (sidePawn&Black != 0) != isBlack {
-            if lastMove.Start.Row == sidePawnPos.Row+2*direction(isBlack) && lastMove.End == sidePawnPos {
-                return true
-            }
+    c := castlingFor(b.rooks(), king, isBlack, kingside)
+    if b.GetPieceAt(c.rookFrom) != NewPiece(Rook, colorOf(isBlack)) {
+        return false
+    }
+    from, to := c.span()
+    for col := from; col <= to; col++ {
+        pos := Position{king.Row, col}
+        if pos != c.kingFrom && pos != c.rookFrom && !b.IsEmpty(pos) {
+            return false
         }
     }
-    return false
+    return !b.IsCheck(isBlack)
 }
 
+// isEnemyPiece checks if the piece belongs to the enemy based on the current player's color
+func (b *Board) isEnemyPiece(piece Piece, isBlack bool) bool {
+    return piece != NoPiece && piece.Color() != colorOf(isBlack)
+}
 
+// IsDrawByFiftyMoveRule reports whether a draw may be claimed because 50
+// moves by each side, 100 plies, went by without a capture or pawn move.
+func (b *Board) IsDrawByFiftyMoveRule() bool {
+    return b.FiftyMoveCount >= 100
+}
 
-func (b *Board) canCastleKingside(isBlack bool) bool {
-    if isBlack {
-        return !b.BlackKingMoved && !b.BlackRookMoved[1] && b.isPathClear(Position{7, 4}, Position{7, 7}) && !b.IsCheck(true)
-    }
-    return !b.WhiteKingMoved && !b.WhiteRookMoved[1] && b.isPathClear(Position{0, 4}, Position{0, 7}) && !b.IsCheck(false)
+// IsDrawBySeventyFiveMoveRule reports whether 75 moves by each side went by
+// without a capture or pawn move, which ends the game without a claim.
+func (b *Board) IsDrawBySeventyFiveMoveRule() bool {
+    return b.FiftyMoveCount >= 150
 }
 
-func (b *Board) canCastleQueenside(isBlack bool) bool {
-    if isBlack {
-        return !b.BlackKingMoved && !b.BlackRookMoved[0] && b.isPathClear(Position{7, 4}, Position{7, 0}) && !b.IsCheck(true)
-    }
-    return !b.WhiteKingMoved && !b.WhiteRookMoved[0] && b.isPathClear(Position{0, 4}, Position{0, 0}) && !b.IsCheck(false)
+// IsDrawByThreefoldRepetition reports whether a draw may be claimed because
+// the position has occurred three times.
+func (b *Board) IsDrawByThreefoldRepetition() bool {
+    return b.repetitions() >= 3
 }
 
-// isEnemyPiece checks if the piece belongs to the enemy based on the current player's color
-func (b *Board) isEnemyPiece(piece int, isBlack bool) bool {
-    if isBlack {
-        return (piece & White) != 0 // True if the piece is White
-    }
-    return (piece & Black) != 0 // True if the piece is Black
+// IsDrawByFivefoldRepetition reports whether the position has occurred five
+// times, which ends the game without a claim.
+func (b *Board) IsDrawByFivefoldRepetition() bool {
+    return b.repetitions() >= 5
 }
 
-func (b *Board) IsDrawByFiftyMoveRule() bool {
-    return b.FiftyMoveCount >= 50
+// IsInsufficientMaterial reports whether neither side can possibly deliver
+// checkmate: king against king, king and a single minor piece against king,
+// or kings and any number of bishops that all stand on one square color.
+func (b *Board) IsInsufficientMaterial() bool {
+    knights, bishops := 0, 0
+    var bishopSquares [2]bool // Light and dark squares holding a bishop
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            switch b.Squares[row][col].Type() {
+            case NoPieceType, King:
+            case Knight:
+                knights++
+            case Bishop:
+                bishops++
+                bishopSquares[(row+col)%2] = true
+            default:
+                return false // A pawn, rook or queen can always mate
+            }
+        }
+    }
+
+    if knights+bishops <= 1 {
+        return true
+    }
+    return knights == 0 && !(bishopSquares[0] && bishopSquares[1])
 }
 
-func (b *Board) IsDrawByThreefoldRepetition() bool {
-    for _, count := range b.PositionHistory {
-        if count >= 3 {
-            return true
+// repetitions counts how often the current position has occurred, including
+// now. Only positions since the last capture or pawn move can repeat.
+func (b *Board) repetitions() int {
+    count, hash := 1, b.Hash()
+    for i := len(b.history) - 2; i >= 0 && i >= len(b.history)-b.FiftyMoveCount; i -= 2 {
+        if b.history[i].hash == hash {
+            count++
         }
     }
-    return false
+    return count
 }
@@ -0,0 +1,66 @@
+package board
+
+import "encoding/json"
+
+// Square is a square index from 0 (a1) to 63 (h8), rank by rank. It is the
+// index used by bitboards; Position is the row and column form.
+type Square int
+
+// NoSquare marks the absence of a square, like NoPosition.
+const NoSquare Square = -1
+
+// Square returns the index of pos, or NoSquare if pos is off the board.
+func (p Position) Square() Square {
+    if !isWithinBounds(p) {
+        return NoSquare
+    }
+    return Square(p.Row*8 + p.Col)
+}
+
+// Position returns the row and column of the square, or NoPosition.
+func (s Square) Position() Position {
+    if s < 0 || s > 63 {
+        return NoPosition
+    }
+    return Position{int(s) / 8, int(s) % 8}
+}
+
+// String returns the algebraic name of the square, such as "e4", or "-".
+func (s Square) String() string {
+    return s.Position().String()
+}
+
+// ParseSquare parses an algebraic square name such as "e4".
+func ParseSquare(name string) (Square, error) {
+    pos, err := ParsePosition(name)
+    if err != nil {
+        return NoSquare, err
+    }
+    return pos.Square(), nil
+}
+
+// MarshalJSON encodes a square by name, or null for NoSquare.
+func (s Square) MarshalJSON() ([]byte, error) {
+    if s.Position() == NoPosition {
+        return []byte("null"), nil
+    }
+    return json.Marshal(s.String())
+}
+
+// UnmarshalJSON decodes a square name or null.
+func (s *Square) UnmarshalJSON(data []byte) error {
+    if string(data) == "null" {
+        *s = NoSquare
+        return nil
+    }
+    var name string
+    if err := json.Unmarshal(data, &name); err != nil {
+        return err
+    }
+    sq, err := ParseSquare(name)
+    if err != nil {
+        return err
+    }
+    *s = sq
+    return nil
+}
@@ -0,0 +1,118 @@
+package board
+
+import (
+    "errors"
+    "fmt"
+)
+
+var ErrInvalidUCI = errors.New("board: malformed UCI move")
+
+// String returns the algebraic name of a square, such as "e4", or "-" for a
+// position off the board.
+func (p Position) String() string {
+    if !isWithinBounds(p) {
+        return "-"
+    }
+    return string([]byte{byte('a' + p.Col), byte('1' + p.Row)})
+}
+
+// ParsePosition parses an algebraic square name such as "e4".
+func ParsePosition(s string) (Position, error) {
+    if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+        return NoPosition, fmt.Errorf("board: invalid square %q", s)
+    }
+    return Position{Row: int(s[1] - '1'), Col: int(s[0] - 'a')}, nil
+}
+
+// UCI returns the move in the long algebraic notation used by the UCI
+// protocol, such as "e2e4" or "e7e8q".
+func (m Move) UCI() string {
+    s := m.Start.String() + m.End.String()
+    if m.Promotion != NoPieceType {
+        s += string(pieceChar(NewPiece(m.Promotion, Black)))
+    }
+    return s
+}
+
+// FormatUCI returns move in UCI notation. With chess960 set, as under the
+// UCI_Chess960 option, castling is written as the king taking its own rook;
+// otherwise as the king moving two squares.
+func (b *Board) FormatUCI(move Move, chess960 bool) string {
+    if c, ok := b.castlingOf(move); ok {
+        move.End = c.kingTo
+        if chess960 {
+            move.End = c.rookFrom
+        }
+    }
+    return move.UCI()
+}
+
+// ParseUCIMove parses a move in UCI long algebraic notation and checks that
+// it is legal in the position. The returned move is ready for MakeMove. A
+// move that does not parse is reported as ErrInvalidUCI, and an illegal one
+// with the same errors as TryMove.
+func ParseUCIMove(b *Board, s string) (Move, error) {
+    move, err := ParseUCI(s)
+    if err != nil {
+        return Move{}, err
+    }
+    return b.validateMove(move)
+}
+
+// ParseChess960UCIMove is ParseUCIMove for a GUI that has set UCI_Chess960,
+// which writes castling as the king taking its own rook even in a standard
+// game.
+func ParseChess960UCIMove(b *Board, s string) (Move, error) {
+    move, err := ParseUCI(s)
+    if err != nil {
+        return Move{}, err
+    }
+
+    // A Chess960 board already writes castling this way
+    piece := b.GetPieceAt(move.Start)
+    if !b.Chess960 && piece.Type() == King && b.GetPieceAt(move.End) == NewPiece(Rook, piece.Color()) {
+        isBlack := piece.Color() == Black
+        c := castlingFor(standardRooks, move.Start, isBlack, move.End.Col > move.Start.Col)
+        if move.Start.Row == homeRow(isBlack) && move.End == c.rookFrom {
+            move.End = c.kingTo
+        }
+    }
+    return b.validateMove(move)
+}
+
+// ParseUCI parses a move in UCI long algebraic notation, such as "e2e4" or
+// "e7e8n", without checking it against a position. A move that does not
+// parse is reported as ErrInvalidUCI. Use ParseUCIMove to also check that
+// the move is legal.
+func ParseUCI(s string) (Move, error) {
+    if len(s) != 4 && len(s) != 5 {
+        return Move{}, fmt.Errorf("%w: %q", ErrInvalidUCI, s)
+    }
+    start, err := ParsePosition(s[0:2])
+    if err != nil {
+        return Move{}, fmt.Errorf("%w: %q", ErrInvalidUCI, s)
+    }
+    end, err := ParsePosition(s[2:4])
+    if err != nil {
+        return Move{}, fmt.Errorf("%w: %q", ErrInvalidUCI, s)
+    }
+
+    move := Move{Start: start, End: end}
+    if len(s) == 5 {
+        if move.Promotion, err = ParsePromotion(s[4:]); err != nil {
+            return Move{}, fmt.Errorf("%w: invalid promotion piece in %q", ErrInvalidUCI, s)
+        }
+    }
+    return move, nil
+}
+
+// ParsePromotion parses the promotion letter of a UCI move: "q", "r", "b" or
+// "n". Anything else is reported as ErrInvalidPromotion.
+func ParsePromotion(s string) (PieceType, error) {
+    if len(s) == 1 {
+        if piece, ok := pieceChars[s[0]]; ok && piece.Color() == Black && isPromotionPiece(piece.Type()) {
+            return piece.Type(), nil
+        }
+    }
+    return NoPieceType, fmt.Errorf("%w: %q", ErrInvalidPromotion, s)
+}
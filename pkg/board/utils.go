@@ -22,4 +22,21 @@ func direction(isBlack bool) int {
         return -1
     }
     return 1
+}
+
+// colorOf returns the color of the given side.
+func colorOf(isBlack bool) Color {
+    if isBlack {
+        return Black
+    }
+    return White
+}
+
+
+// homeRow returns the back rank of the given side.
+func homeRow(isBlack bool) int {
+    if isBlack {
+        return 7
+    }
+    return 0
 }
\ No newline at end of file
@@ -0,0 +1,118 @@
+package board
+
+// Zobrist keys: a random 64-bit number per piece and square, for the side to
+// move, for each castling rights combination and for each en passant file. A
+// position's hash is the XOR of the keys that describe it.
+var (
+    zobristPieces    [2][7][64]uint64 // Indexed by color index, piece type and bit index
+    zobristBlack     uint64
+    zobristCastling  [16]uint64
+    zobristEnPassant [8]uint64
+)
+
+func init() {
+    // A fixed seed keeps hashes stable between runs
+    rng := xorshift64(0x9E3779B97F4A7C15)
+    for color := 0; color < 2; color++ {
+        for pieceType := Rook; pieceType <= Pawn; pieceType++ {
+            for sq := 0; sq < 64; sq++ {
+                zobristPieces[color][pieceType][sq] = rng.next()
+            }
+        }
+    }
+    zobristBlack = rng.next()
+    for i := range zobristCastling {
+        zobristCastling[i] = rng.next()
+    }
+    for i := range zobristEnPassant {
+        zobristEnPassant[i] = rng.next()
+    }
+}
+
+// Hash returns the Zobrist hash of the position, covering the pieces, side to
+// move, castling rights and any en passant capture that is possible. It is
+// kept up to date by SetPieceAt, MakeMove and UnmakeMove.
+func (b *Board) Hash() uint64 {
+    b.sync()
+    return b.hash
+}
+
+// PawnHash returns a Zobrist hash of the pawns of both colors and nothing
+// else, for caching pawn structure evaluations. It is kept up to date by
+// SetPieceAt, MakeMove and UnmakeMove.
+func (b *Board) PawnHash() uint64 {
+    b.sync()
+    return b.pawnHash
+}
+
+// toggleHash adds piece on pos to the hashes, or takes it out again.
+func (b *Board) toggleHash(piece Piece, pos Position) {
+    key := pieceKey(piece, pos)
+    b.hash ^= key
+    if piece.Type() == Pawn {
+        b.pawnHash ^= key
+    }
+}
+
+// computeHash calculates the Zobrist hash from scratch.
+func (b *Board) computeHash() uint64 {
+    var h uint64
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            if piece := b.Squares[row][col]; piece != NoPiece {
+                h ^= pieceKey(piece, Position{row, col})
+            }
+        }
+    }
+    if b.CurrentTurn == Black {
+        h ^= zobristBlack
+    }
+    h ^= zobristCastling[b.Castling]
+    h ^= b.enPassantKey()
+    return h
+}
+
+// computePawnHash calculates the pawn hash from scratch.
+func (b *Board) computePawnHash() uint64 {
+    var h uint64
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            if piece := b.Squares[row][col]; piece.Type() == Pawn {
+                h ^= pieceKey(piece, Position{row, col})
+            }
+        }
+    }
+    return h
+}
+
+// pieceKey returns the Zobrist key of piece standing on pos.
+func pieceKey(piece Piece, pos Position) uint64 {
+    return zobristPieces[colorIndex(piece.Color())][piece.Type()][pos.Square()]
+}
+
+// enPassantKey returns the en passant part of the hash. The square only counts
+// when a pawn of the side to move stands ready to capture onto it, so that
+// repetitions are not missed after a harmless double push.
+func (b *Board) enPassantKey() uint64 {
+    if !isWithinBounds(b.EnPassant) {
+        return 0
+    }
+    isBlack := b.CurrentTurn == Black
+    for _, dc := range [2]int{-1, 1} {
+        start := Position{b.EnPassant.Row - direction(isBlack), b.EnPassant.Col + dc}
+        if isWithinBounds(start) && b.GetPieceAt(start) == NewPiece(Pawn, b.CurrentTurn) && b.canCaptureEnPassant(start, b.EnPassant, isBlack) {
+            return zobristEnPassant[b.EnPassant.Col]
+        }
+    }
+    return 0
+}
+
+// xorshift64 is a small deterministic generator for the Zobrist keys.
+type xorshift64 uint64
+
+func (x *xorshift64) next() uint64 {
+    *x ^= *x << 13
+    *x ^= *x >> 7
+    *x ^= *x << 17
+    return uint64(*x)
+}
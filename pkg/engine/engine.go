@@ -38,7 +38,7 @@ func (e *Engine) Run() {
 
     // Example placeholder logic - replace with actual engine loop
     // move := search.Search()   // Perform a search to find the best move
-    // score := eval.Evaluate()  // Evaluate the board state
+    // score := eval.Evaluate(e.Board) // Evaluate the board state
 
     // fmt.Printf("Best move found: %v with score: %d\n", move, score)
 
@@ -0,0 +1,311 @@
+// pkg/render/render.go
+package render
+
+import (
+    "fmt"
+    "html"
+    "math"
+    "strings"
+
+    "github.com/colmak/go-chess-go/pkg/board"
+)
+
+// DefaultSize is the width and height of the image when Options.Size is 0.
+const DefaultSize = 360
+
+// Colors used for the board and its markings.
+const (
+    lightSquare    = "#f0d9b5"
+    darkSquare     = "#b58863"
+    lastMoveColor  = "#cdd26a"
+    highlightColor = "#6aa3d2"
+    checkColor     = "#e0403a"
+    arrowColor     = "#15781b"
+)
+
+// Arrow is an arrow drawn from the center of one square to another.
+type Arrow struct {
+    From, To board.Position
+    Color    string // Any SVG color; a dark green if empty
+}
+
+// Options controls what SVG draws besides the pieces.
+type Options struct {
+    Size        int         // Width and height in pixels; DefaultSize if 0
+    Orientation board.Color // The side shown at the bottom; White if unset
+    Coordinates bool        // Label the files and ranks along the edges
+    LastMove    bool        // Shade the start and end squares of the board's last move
+    Check       bool        // Mark the king of the side to move when it is in check
+    Highlights  []board.Position
+    Arrows      []Arrow
+}
+
+// pieceShape is a piece drawn in a 45 by 45 box, as SVG path data. The
+// outlines are filled with the piece's color, in order; the details are lines
+// drawn over them in the other color, and the marks lines in the outline
+// color.
+type pieceShape struct {
+    outlines []string
+    details  string
+    marks    string
+}
+
+// Pieces are drawn from paths rather than font glyphs, so the image looks the
+// same everywhere and needs no external resources.
+var pieceShapes = map[board.PieceType]pieceShape{
+    board.King: {
+        outlines: []string{"M11,39 34,39 34,36 33,33 37,24 35,18 29,17 22.5,21 16,17 10,18 8,24 12,33 11,36z"},
+        details:  "M12,33L33,33M11,36L34,36M22.5,21L22.5,31",
+        marks:    "M22.5,4L22.5,15M19,7.5L26,7.5",
+    },
+    board.Queen: {
+        outlines: []string{
+            "M12,33 9,13 15,26 15.5,10 19.5,24 22.5,9 25.5,24 29.5,10 30,26 36,13 33,33 34,36 34,39 11,39 11,36z",
+            "M7,11a2,2 0 1 0 4,0a2,2 0 1 0 -4,0z",
+            "M13.5,8a2,2 0 1 0 4,0a2,2 0 1 0 -4,0z",
+            "M20.5,7a2,2 0 1 0 4,0a2,2 0 1 0 -4,0z",
+            "M27.5,8a2,2 0 1 0 4,0a2,2 0 1 0 -4,0z",
+            "M34,11a2,2 0 1 0 4,0a2,2 0 1 0 -4,0z",
+        },
+        details: "M12,33L33,33M11,36L34,36",
+    },
+    board.Rook: {
+        outlines: []string{"M9,39 36,39 36,35 32,35 31,17 34,14 34,9 30,9 30,11.5 25,11.5 25,9 20,9 20,11.5 15,11.5 15,9 11,9 11,14 14,17 13,35 9,35z"},
+        details:  "M14,17L31,17M13,32L32,32",
+    },
+    board.Bishop: {
+        outlines: []string{
+            "M20,8a2.5,2.5 0 1 0 5,0a2.5,2.5 0 1 0 -5,0z",
+            "M22.5,10.5 28,16 30,22 27,27 28,31 27,33 34,36 34,39 11,39 11,36 18,33 17,31 18,27 15,22 17,16z",
+        },
+        details: "M22.5,15L22.5,23M18.5,19L26.5,19M18,27L27,27M18,31L27,31",
+    },
+    board.Knight: {
+        outlines: []string{"M12,39 35,39 34,30 33,22 30,15 25,11 23,6 20.5,10.5 17,13 12,18 9,24 10,27.5 13,28 16,25.5 21,24 16,31 13,34z"},
+        details:  "M16.5,17L17.5,17", // The eye
+    },
+    board.Pawn: {
+        outlines: []string{
+            "M17.5,14a5,5 0 1 0 10,0a5,5 0 1 0 -10,0z",
+            "M20,18.5 25,18.5 26.5,21.5 25,23.5 27,31 31,34 32,39 13,39 14,34 18,31 20,23.5 18.5,21.5z",
+        },
+    },
+}
+
+// SVG draws the board as a self-contained SVG image.
+func SVG(b *board.Board, opts Options) string {
+    size := opts.Size
+    if size <= 0 {
+        size = DefaultSize
+    }
+    sq := float64(size) / 8
+
+    // x and y of the top left corner of a square
+    corner := func(pos board.Position) (float64, float64) {
+        if opts.Orientation == board.Black {
+            return float64(7-pos.Col) * sq, float64(pos.Row) * sq
+        }
+        return float64(pos.Col) * sq, float64(7-pos.Row) * sq
+    }
+
+    var sb strings.Builder
+    fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, size, size, size, size)
+    sb.WriteByte('\n')
+    writePieceDefs(&sb, b)
+
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            color := darkSquare
+            if (row+col)%2 == 1 {
+                color = lightSquare
+            }
+            x, y := corner(board.Position{Row: row, Col: col})
+            writeRect(&sb, x, y, sq, color, 1)
+        }
+    }
+
+    if opts.LastMove {
+        if last := b.GetLastMove(); last.Start != last.End {
+            for _, pos := range [2]board.Position{last.Start, last.End} {
+                x, y := corner(pos)
+                writeRect(&sb, x, y, sq, lastMoveColor, 0.8)
+            }
+        }
+    }
+    for _, pos := range opts.Highlights {
+        x, y := corner(pos)
+        writeRect(&sb, x, y, sq, highlightColor, 0.6)
+    }
+    if opts.Check {
+        if king, ok := checkedKing(b); ok {
+            x, y := corner(king)
+            fmt.Fprintf(&sb, `<circle cx="%s" cy="%s" r="%s" fill="%s" fill-opacity="0.7"/>`,
+                num(x+sq/2), num(y+sq/2), num(sq*0.45), checkColor)
+            sb.WriteByte('\n')
+        }
+    }
+
+    if opts.Coordinates {
+        font := num(sq * 0.2)
+        for i := 0; i < 8; i++ {
+            file := board.Position{Row: 0, Col: i}
+            rank := board.Position{Row: i, Col: 0}
+            if opts.Orientation == board.Black {
+                file.Row, rank.Col = 7, 7
+            }
+            x, y := corner(file)
+            fmt.Fprintf(&sb, `<text x="%s" y="%s" font-family="sans-serif" font-size="%s" text-anchor="end" fill="%s">%c</text>`,
+                num(x+sq*0.95), num(y+sq*0.95), font, labelColor(file), 'a'+i)
+            sb.WriteByte('\n')
+            x, y = corner(rank)
+            fmt.Fprintf(&sb, `<text x="%s" y="%s" font-family="sans-serif" font-size="%s" fill="%s">%c</text>`,
+                num(x+sq*0.05), num(y+sq*0.25), font, labelColor(rank), '1'+i)
+            sb.WriteByte('\n')
+        }
+    }
+
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            pos := board.Position{Row: row, Col: col}
+            piece := b.GetPieceAt(pos)
+            if piece == board.NoPiece {
+                continue
+            }
+            x, y := corner(pos)
+            fmt.Fprintf(&sb, `<use href="#%s" x="%s" y="%s" width="%s" height="%s"/>`,
+                pieceID(piece), num(x), num(y), num(sq), num(sq))
+            sb.WriteByte('\n')
+        }
+    }
+
+    for _, arrow := range opts.Arrows {
+        writeArrow(&sb, arrow, corner, sq)
+    }
+
+    sb.WriteString("</svg>\n")
+    return sb.String()
+}
+
+// checkedKing returns the square of the side to move's king if it is in
+// check.
+func checkedKing(b *board.Board) (board.Position, bool) {
+    if !b.IsCheck(b.CurrentTurn == board.Black) {
+        return board.NoPosition, false
+    }
+    king := b.KingPosition(b.CurrentTurn)
+    return king, king != board.NoPosition
+}
+
+// writePieceDefs defines a symbol for each kind of piece on the board, once,
+// for the squares to <use>.
+func writePieceDefs(sb *strings.Builder, b *board.Board) {
+    var used [2][7]bool
+    empty := true
+    for row := 0; row < 8; row++ {
+        for col := 0; col < 8; col++ {
+            if piece := b.GetPieceAt(board.Position{Row: row, Col: col}); piece != board.NoPiece {
+                used[colorIndex(piece.Color())][piece.Type()] = true
+                empty = false
+            }
+        }
+    }
+    if empty {
+        return
+    }
+
+    sb.WriteString("<defs>\n")
+    for _, color := range [2]board.Color{board.White, board.Black} {
+        fill, detail := "#fff", "#000"
+        if color == board.Black {
+            fill, detail = "#000", "#fff"
+        }
+        for pieceType := board.Rook; pieceType <= board.Pawn; pieceType++ {
+            if !used[colorIndex(color)][pieceType] {
+                continue
+            }
+            shape := pieceShapes[pieceType]
+            fmt.Fprintf(sb, `<symbol id="%s" viewBox="0 0 45 45" fill="%s" stroke="#000" stroke-width="1.5" stroke-linejoin="round" stroke-linecap="round">`,
+                pieceID(board.NewPiece(pieceType, color)), fill)
+            for _, d := range shape.outlines {
+                fmt.Fprintf(sb, `<path d="%s"/>`, d)
+            }
+            if shape.details != "" {
+                fmt.Fprintf(sb, `<path d="%s" fill="none" stroke="%s"/>`, shape.details, detail)
+            }
+            if shape.marks != "" {
+                fmt.Fprintf(sb, `<path d="%s" fill="none" stroke-width="2"/>`, shape.marks)
+            }
+            sb.WriteString("</symbol>\n")
+        }
+    }
+    sb.WriteString("</defs>\n")
+}
+
+// pieceID names a piece's symbol: "w" or "b" followed by the lower case FEN
+// letter.
+func pieceID(piece board.Piece) string {
+    prefix := "w"
+    if piece.Color() == board.Black {
+        prefix = "b"
+    }
+    return prefix + strings.ToLower(piece.String())
+}
+
+// colorIndex is 0 for White and 1 for Black.
+func colorIndex(color board.Color) int {
+    if color == board.Black {
+        return 1
+    }
+    return 0
+}
+
+// labelColor draws coordinates in the color of the other kind of square.
+func labelColor(pos board.Position) string {
+    if (pos.Row+pos.Col)%2 == 1 {
+        return darkSquare
+    }
+    return lightSquare
+}
+
+func writeRect(sb *strings.Builder, x, y, size float64, color string, opacity float64) {
+    fmt.Fprintf(sb, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"`, num(x), num(y), num(size), num(size), color)
+    if opacity < 1 {
+        fmt.Fprintf(sb, ` fill-opacity="%s"`, num(opacity))
+    }
+    sb.WriteString("/>\n")
+}
+
+// writeArrow draws an arrow as a line ending in a triangular head that
+// stops at the center of the target square.
+func writeArrow(sb *strings.Builder, arrow Arrow, corner func(board.Position) (float64, float64), sq float64) {
+    color := html.EscapeString(arrow.Color)
+    if color == "" {
+        color = arrowColor
+    }
+    x1, y1 := corner(arrow.From)
+    x2, y2 := corner(arrow.To)
+    x1, y1, x2, y2 = x1+sq/2, y1+sq/2, x2+sq/2, y2+sq/2
+
+    length := math.Hypot(x2-x1, y2-y1)
+    if length == 0 {
+        return
+    }
+    dx, dy := (x2-x1)/length, (y2-y1)/length
+    head, width := sq*0.4, sq*0.18
+
+    // The shaft ends where the head begins
+    bx, by := x2-dx*head, y2-dy*head
+    fmt.Fprintf(sb, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s" stroke-opacity="0.8"/>`,
+        num(x1), num(y1), num(bx), num(by), color, num(width))
+    sb.WriteByte('\n')
+    fmt.Fprintf(sb, `<polygon points="%s,%s %s,%s %s,%s" fill="%s" fill-opacity="0.8"/>`,
+        num(x2), num(y2), num(bx-dy*head/2), num(by+dx*head/2), num(bx+dy*head/2), num(by-dx*head/2), color)
+    sb.WriteByte('\n')
+}
+
+// num formats a coordinate with at most two decimals.
+func num(f float64) string {
+    s := fmt.Sprintf("%.2f", f)
+    s = strings.TrimRight(s, "0")
+    return strings.TrimSuffix(s, ".")
+}
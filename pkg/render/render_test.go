@@ -0,0 +1,115 @@
+package render_test
+
+import (
+    "encoding/xml"
+    "io"
+    "strings"
+    "testing"
+
+    "github.com/colmak/go-chess-go/pkg/board"
+    "github.com/colmak/go-chess-go/pkg/render"
+)
+
+// elements parses svg and counts its elements by name.
+func elements(t *testing.T, svg string) map[string]int {
+    t.Helper()
+    counts := make(map[string]int)
+    d := xml.NewDecoder(strings.NewReader(svg))
+    for {
+        tok, err := d.Token()
+        if err == io.EOF {
+            return counts
+        }
+        if err != nil {
+            t.Fatalf("Expected well-formed SVG, got %v\n%s", err, svg)
+        }
+        if start, ok := tok.(xml.StartElement); ok {
+            counts[start.Name.Local]++
+        }
+    }
+}
+
+func TestSVGStartPosition(t *testing.T) {
+    svg := render.SVG(board.NewBoard(), render.Options{})
+    if !strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="360" height="360"`) {
+        t.Errorf("Expected a 360 pixel image, got %q", svg[:80])
+    }
+    counts := elements(t, svg)
+    if counts["rect"] != 64 || counts["use"] != 32 || counts["text"] != 0 {
+        t.Errorf("Expected 64 squares and 32 pieces, got %v", counts)
+    }
+    if counts["symbol"] != 12 {
+        t.Errorf("Expected each kind of piece to be defined once, got %d symbols", counts["symbol"])
+    }
+    if counts["line"] != 0 || counts["circle"] != 0 {
+        t.Errorf("Expected no markings, got %v", counts)
+    }
+}
+
+func TestSVGMarkings(t *testing.T) {
+    b, err := board.FromFEN("rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3")
+    if err != nil {
+        t.Fatal(err)
+    }
+    opts := render.Options{
+        Size:        480,
+        Coordinates: true,
+        Check:       true,
+        Highlights:  []board.Position{{Row: 3, Col: 4}},
+        Arrows:      []render.Arrow{{From: board.Position{Row: 7, Col: 3}, To: board.Position{Row: 3, Col: 7}, Color: `red"/><script/>`}},
+    }
+    svg := render.SVG(b, opts)
+    counts := elements(t, svg)
+    if counts["rect"] != 65 {
+        t.Errorf("Expected one highlighted square, got %d rects", counts["rect"])
+    }
+    if counts["circle"] != 1 {
+        t.Error("Expected the king in check to be marked")
+    }
+    if counts["line"] != 1 || counts["polygon"] != 1 {
+        t.Errorf("Expected one arrow, got %v", counts)
+    }
+    if counts["script"] != 0 {
+        t.Error("Expected the arrow color to be escaped")
+    }
+    if counts["use"] != 32 || counts["text"] != 16 {
+        t.Errorf("Expected 32 pieces and 16 labels, got %v", counts)
+    }
+}
+
+func TestSVGOrientation(t *testing.T) {
+    b := board.NewBoard()
+    white := render.SVG(b, render.Options{Size: 80})
+    black := render.SVG(b, render.Options{Size: 80, Orientation: board.Black})
+    if white == black {
+        t.Fatal("Expected the flipped board to differ")
+    }
+
+    // With White at the bottom the a1 rook is drawn in the bottom left
+    // square; with Black at the bottom it moves to the top right
+    if !strings.Contains(white, `<use href="#wr" x="0" y="70" width="10" height="10"/>`) {
+        t.Errorf("Expected the a1 rook at the bottom left")
+    }
+    if !strings.Contains(black, `<use href="#wr" x="70" y="0" width="10" height="10"/>`) {
+        t.Errorf("Expected the a1 rook at the top right")
+    }
+}
+
+func TestSVGDefinesOnlyPiecesOnTheBoard(t *testing.T) {
+    b, err := board.FromFEN("4k3/8/8/8/8/8/4P3/4K3 w - - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    svg := render.SVG(b, render.Options{})
+    for _, id := range []string{"wk", "wp", "bk"} {
+        if strings.Count(svg, `<symbol id="`+id+`"`) != 1 {
+            t.Errorf("Expected %s to be defined once", id)
+        }
+    }
+    if counts := elements(t, svg); counts["symbol"] != 3 || counts["use"] != 3 {
+        t.Errorf("Expected three pieces drawn from three symbols, got %v", counts)
+    }
+    if empty := render.SVG(&board.Board{}, render.Options{}); strings.Contains(empty, "<defs>") {
+        t.Error("Expected an empty board to define no pieces")
+    }
+}
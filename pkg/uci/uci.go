@@ -1,10 +1,127 @@
 // pkg/uci/uci.go
 package uci
 
-import "fmt"
+import (
+    "bufio"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
 
-// Start initializes UCI communication.
+    "github.com/colmak/go-chess-go/pkg/board"
+)
+
+// Options holds the engine options a GUI can change with setoption.
+type Options struct {
+    // Chess960 is the UCI_Chess960 option. When set, castling is sent and
+    // received as the king taking its own rook, in every game.
+    Chess960 bool
+}
+
+// OptionDeclarations lists the "option" lines sent in reply to "uci".
+var OptionDeclarations = []string{
+    "option name UCI_Chess960 type check default false",
+}
+
+// SetOption sets an option by its UCI name, which is not case sensitive.
+func (o *Options) SetOption(name, value string) error {
+    switch strings.ToLower(name) {
+    case "uci_chess960":
+        v, err := strconv.ParseBool(value)
+        if err != nil {
+            return fmt.Errorf("uci: invalid value %q for %s", value, name)
+        }
+        o.Chess960 = v
+        return nil
+    }
+    return fmt.Errorf("uci: unknown option %q", name)
+}
+
+// ParseMove parses a move from the GUI and checks that it is legal in the
+// position, reading castling as the UCI_Chess960 option says.
+func (o Options) ParseMove(b *board.Board, s string) (board.Move, error) {
+    if o.Chess960 {
+        return board.ParseChess960UCIMove(b, s)
+    }
+    return board.ParseUCIMove(b, s)
+}
+
+// FormatMove returns a legal move of the position in the notation the GUI
+// expects.
+func (o Options) FormatMove(b *board.Board, move board.Move) string {
+    return b.FormatUCI(move, o.Chess960)
+}
+
+// Start speaks UCI on standard input and output until the GUI sends "quit".
 func Start() {
-    fmt.Println("UCI protocol started")
-    // Implement UCI command handling here
+    var o Options
+    if err := o.Run(os.Stdin, os.Stdout); err != nil {
+        fmt.Fprintln(os.Stderr, err)
+    }
+}
+
+// Run reads UCI commands from r and writes the replies to w until "quit" or
+// the end of the input. It answers the "uci" handshake with the engine's id
+// and OptionDeclarations before "uciok", "isready" with "readyok", and
+// applies "setoption" to o. Other commands are ignored.
+func (o *Options) Run(r io.Reader, w io.Writer) error {
+    scanner := bufio.NewScanner(r)
+    for scanner.Scan() {
+        fields := strings.Fields(scanner.Text())
+        if len(fields) == 0 {
+            continue
+        }
+        switch fields[0] {
+        case "uci":
+            fmt.Fprintln(w, "id name Go Chess Go")
+            fmt.Fprintln(w, "id author the Go Chess Go authors")
+            for _, option := range OptionDeclarations {
+                fmt.Fprintln(w, option)
+            }
+            fmt.Fprintln(w, "uciok")
+        case "isready":
+            fmt.Fprintln(w, "readyok")
+        case "setoption":
+            name, value := parseSetOption(fields[1:])
+            if err := o.SetOption(name, value); err != nil {
+                fmt.Fprintf(w, "info string %v\n", err)
+            }
+        case "quit":
+            return nil
+        }
+    }
+    if err := scanner.Err(); err != nil {
+        return fmt.Errorf("uci: %w", err)
+    }
+    return nil
+}
+
+// parseSetOption splits the arguments of "setoption name <id> [value <x>]".
+// Both the name and the value may contain spaces.
+func parseSetOption(args []string) (name, value string) {
+    var names, values []string
+    target := &names
+    for i, arg := range args {
+        switch {
+        case i == 0 && arg == "name":
+        case arg == "value" && target == &names:
+            target = &values
+        default:
+            *target = append(*target, arg)
+        }
+    }
+    return strings.Join(names, " "), strings.Join(values, " ")
+}
+
+// ParseMove parses a move in long algebraic notation, such as "e2e4" or
+// "e7e8n", as board.ParseUCI does. The move is not checked against any
+// position; use Options.ParseMove for that.
+func ParseMove(s string) (board.Move, error) {
+    return board.ParseUCI(s)
+}
+
+// FormatMove returns a move in long algebraic notation.
+func FormatMove(move board.Move) string {
+    return move.UCI()
 }
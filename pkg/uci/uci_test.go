@@ -2,7 +2,12 @@
 package uci_test // Adjust the package name according to the folder, e.g., board_test, uci_test, etc.
 
 import (
+    "errors"
+    "strings"
     "testing"
+
+    "github.com/colmak/go-chess-go/pkg/board"
+    "github.com/colmak/go-chess-go/pkg/uci"
 )
 
 // TestMain initializes the package and verifies no errors during startup.
@@ -34,3 +39,82 @@ func TestBasicFunctionality(t *testing.T) {
         t.Errorf("Basic functionality failed; expected 2, got something else")
     }
 }
+
+func TestParseMove(t *testing.T) {
+    move, err := uci.ParseMove("e7e8n")
+    if err != nil {
+        t.Fatalf("Expected e7e8n to parse, got %v", err)
+    }
+    want := board.Move{Start: board.Position{Row: 6, Col: 4}, End: board.Position{Row: 7, Col: 4}, Promotion: board.Knight}
+    if move != want {
+        t.Errorf("Expected %v, got %v", want, move)
+    }
+
+    for _, s := range []string{"", "e2", "e2e9", "i2e4", "e7e8k", "e7e8qq"} {
+        if _, err := uci.ParseMove(s); !errors.Is(err, board.ErrInvalidUCI) {
+            t.Errorf("Expected %q to be rejected with %v, got %v", s, board.ErrInvalidUCI, err)
+        }
+    }
+}
+
+func TestFormatMove(t *testing.T) {
+    for _, s := range []string{"e2e4", "a7a8q", "h2h1r", "b7c8b", "g7f8n"} {
+        move, err := uci.ParseMove(s)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if got := uci.FormatMove(move); got != s {
+            t.Errorf("Expected %q, got %q", s, got)
+        }
+    }
+}
+
+func TestChess960Option(t *testing.T) {
+    var opts uci.Options
+    if err := opts.SetOption("UCI_Chess960", "true"); err != nil {
+        t.Fatal(err)
+    }
+    if !opts.Chess960 {
+        t.Fatal("Expected UCI_Chess960 to be set")
+    }
+    if err := opts.SetOption("UCI_Chess960", "maybe"); err == nil {
+        t.Error("Expected an invalid value to be rejected")
+    }
+    if err := opts.SetOption("Hash", "16"); err == nil {
+        t.Error("Expected an unknown option to be rejected")
+    }
+
+    b, err := board.FromFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    move, err := opts.ParseMove(b, "e1a1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got := opts.FormatMove(b, move); got != "e1a1" {
+        t.Errorf("Expected e1a1, got %q", got)
+    }
+    if got := (uci.Options{}).FormatMove(b, move); got != "e1c1" {
+        t.Errorf("Expected e1c1 without UCI_Chess960, got %q", got)
+    }
+}
+
+func TestHandshake(t *testing.T) {
+    var o uci.Options
+    var out strings.Builder
+    in := "uci\nsetoption name UCI_Chess960 value true\nsetoption name Hash value 16\nisready\nquit\nisready\n"
+    if err := o.Run(strings.NewReader(in), &out); err != nil {
+        t.Fatal(err)
+    }
+
+    want := []string{"id name Go Chess Go", "id author the Go Chess Go authors"}
+    want = append(want, uci.OptionDeclarations...)
+    want = append(want, "uciok", `info string uci: unknown option "Hash"`, "readyok")
+    if got := out.String(); got != strings.Join(want, "\n")+"\n" {
+        t.Errorf("Expected the replies\n%s\ngot\n%s", strings.Join(want, "\n"), got)
+    }
+    if !o.Chess960 {
+        t.Error("Expected setoption to turn on UCI_Chess960")
+    }
+}